@@ -26,6 +26,15 @@ func main() {
 	var force bool
 	var width int
 	var height int
+	var collapse []string
+	var maxDepth int
+	var view string
+	var includeTags []string
+	var excludeTags []string
+	var themeFile string
+	var dark bool
+	var fontFallbacks []string
+	var fontDirs []string
 
 	var rootCmd = &cobra.Command{
 		Use:     "awsdac <input filename>",
@@ -65,6 +74,10 @@ func main() {
 					AllowUntrustedDefinitions: allowUntrustedDefinitions,
 					Width:                     width,
 					Height:                    height,
+					ThemeFile:                 themeFile,
+					Dark:                      dark,
+					FontFallbacks:             fontFallbacks,
+					FontDirs:                  fontDirs,
 				}
 				if force {
 					opts.OverwriteMode = ctl.Force
@@ -82,6 +95,15 @@ func main() {
 					AllowUntrustedDefinitions: allowUntrustedDefinitions,
 					Width:                     width,
 					Height:                    height,
+					Collapse:                  collapse,
+					MaxDepth:                  maxDepth,
+					View:                      view,
+					IncludeTags:               includeTags,
+					ExcludeTags:               excludeTags,
+					ThemeFile:                 themeFile,
+					Dark:                      dark,
+					FontFallbacks:             fontFallbacks,
+					FontDirs:                  fontDirs,
 				}
 				if force {
 					opts.OverwriteMode = ctl.Force
@@ -108,6 +130,15 @@ func main() {
 	rootCmd.PersistentFlags().BoolVarP(&force, "force", "f", false, "Overwrite output file without confirmation")
 	rootCmd.PersistentFlags().IntVar(&width, "width", 0, "Resize output image width (0 means no resizing)")
 	rootCmd.PersistentFlags().IntVar(&height, "height", 0, "Resize output image height (0 means no resizing)")
+	rootCmd.PersistentFlags().StringSliceVar(&collapse, "collapse", nil, "Collapse the given groups into a single box (comma-separated resource names)")
+	rootCmd.PersistentFlags().IntVar(&maxDepth, "max-depth", 0, "Collapse groups nested at this depth from Canvas (0 means no limit)")
+	rootCmd.PersistentFlags().StringVar(&view, "view", "", "Render the named view from the Views section")
+	rootCmd.PersistentFlags().StringSliceVar(&includeTags, "include-tags", nil, "Render only resources and links with any of these tags (comma-separated)")
+	rootCmd.PersistentFlags().StringSliceVar(&excludeTags, "exclude-tags", nil, "Do not render resources and links with any of these tags (comma-separated)")
+	rootCmd.PersistentFlags().StringVar(&themeFile, "theme", "", "Theme file defining the palette and default colors (overridden by the Theme section)")
+	rootCmd.PersistentFlags().BoolVar(&dark, "dark", false, "Render for a dark background")
+	rootCmd.PersistentFlags().StringSliceVar(&fontFallbacks, "font-fallback", nil, "Fonts drawing characters missing from the title fonts, tried in order (comma-separated)")
+	rootCmd.PersistentFlags().StringSliceVar(&fontDirs, "font-dir", nil, "Directories searched for fonts given by file name (comma-separated)")
 
 	if err := rootCmd.Execute(); err != nil {
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
@@ -121,6 +121,11 @@ func CreateDiagramFromCFnTemplate(inputfile string, outputfile *string, generate
 	log.Info("--- Ensuring a single parent for resources with multiple parents ---")
 	ensureSingleParent(&template)
 
+	log.Info("--- Load theme ---")
+	if err := loadTheme(&template, opts); err != nil {
+		return fmt.Errorf("failed to load theme: %w", err)
+	}
+
 	log.Info("--- Load Resources section ---")
 	if err := loadResources(&template, ds, resources); err != nil {
 		return fmt.Errorf("failed to load resources: %w", err)
@@ -137,7 +142,7 @@ func CreateDiagramFromCFnTemplate(inputfile string, outputfile *string, generate
 		go generateDacFileFromCFnTemplate(&template, *outputfile)
 	}
 
-	if err := createDiagram(resources, outputfile, opts); err != nil {
+	if err := createDiagram(&template, resources, nil, nil, outputfile, opts); err != nil {
 		return fmt.Errorf("failed to create diagram: %w", err)
 	}
 	return nil
@@ -8,7 +8,6 @@ import (
 	"fmt"
 	"image"
 	"image/color"
-	"image/png"
 	"math"
 	"os"
 	"sort"
@@ -19,10 +18,30 @@ import (
 	"github.com/awslabs/diagram-as-code/internal/types"
 	log "github.com/sirupsen/logrus"
 	"golang.org/x/exp/maps"
+	"golang.org/x/image/colornames"
 	"golang.org/x/image/draw"
 )
 
+// stringToColor parses rgba(r,g,b,a), #RRGGBB[AA], CSS color names and $name references
+// to the palette of the theme
 func stringToColor(c string) (color.RGBA, error) {
+	c = strings.TrimSpace(c)
+	if name, ok := strings.CutPrefix(c, "$"); ok {
+		pc, exists := palette[name]
+		if !exists {
+			return color.RGBA{}, fmt.Errorf("palette color '%s' is not defined in the theme", name)
+		}
+		return pc, nil
+	}
+	if strings.HasPrefix(c, "#") {
+		return parseHexColor(c)
+	}
+	if strings.EqualFold(c, "transparent") {
+		return color.RGBA{0, 0, 0, 0}, nil
+	}
+	if named, ok := colornames.Map[strings.ToLower(c)]; ok {
+		return named, nil
+	}
 	var r, g, b, a uint8
 	_, err := fmt.Sscanf(c, "rgba(%d,%d,%d,%d)", &r, &g, &b, &a)
 	if err != nil {
@@ -31,6 +50,46 @@ func stringToColor(c string) (color.RGBA, error) {
 	return color.RGBA{r, g, b, a}, nil
 }
 
+// setDefinitionBorderStyle applies the width, corner radius and shadow of a definition border
+func setDefinitionBorderStyle(resource *types.Resource, border *definition.DefinitionBorder) error {
+	if border.Width != 0 {
+		if err := resource.SetBorderWidth(border.Width); err != nil {
+			return err
+		}
+	}
+	if border.CornerRadius != 0 {
+		if err := resource.SetCornerRadius(border.CornerRadius); err != nil {
+			return err
+		}
+	}
+	if border.Shadow {
+		resource.SetShadow(true)
+	}
+	return nil
+}
+
+// convertBadge converts a badge of the template, defaulting to a red badge on the top-right corner
+func convertBadge(b Badge) (types.Badge, error) {
+	position := b.Position
+	if position == "" {
+		position = "NE"
+	}
+	switch position {
+	case "N", "NE", "E", "SE", "S", "SW", "W", "NW":
+	default:
+		return types.Badge{}, fmt.Errorf("unknown badge position %s (allowed: N, NE, E, SE, S, SW, W, NW)", position)
+	}
+	windrose, err := types.ConvertWindrose(position)
+	if err != nil {
+		return types.Badge{}, err
+	}
+	c, err := stringToColor(mergeString(b.Color, "#D13212"))
+	if err != nil {
+		return types.Badge{}, fmt.Errorf("failed to parse badge color: %w", err)
+	}
+	return types.Badge{Text: b.Text, Color: c, Position: windrose}, nil
+}
+
 // OverwriteMode defines how to handle existing output files
 type OverwriteMode int
 
@@ -96,6 +155,21 @@ type Diagram struct {
 	DefinitionFiles []DefinitionFile    `yaml:"DefinitionFiles"`
 	Resources       map[string]Resource `yaml:"Resources"`
 	Links           []Link              `yaml:"Links"`
+	Views           map[string]View     `yaml:"Views"`
+	Pages           []Page              `yaml:"Pages"`
+	LinkCrossing    string              `yaml:"LinkCrossing"` // none, jump or gap
+	Steps           *Steps              `yaml:"Steps"`
+	Theme           *Theme              `yaml:"Theme"`
+	Styles          map[string]Style    `yaml:"Styles"`
+	Legend          *Legend             `yaml:"Legend"`
+	Metadata        *Metadata           `yaml:"Metadata"`
+}
+
+// Steps configures the sequence numbers drawn on links
+type Steps struct {
+	AutoNumber bool   `yaml:"AutoNumber"` // Number links in the order of the Links section
+	Position   string `yaml:"Position"`   // middle or source
+	Legend     bool   `yaml:"Legend"`     // List every step below the diagram
 }
 
 type DefinitionFile struct {
@@ -111,6 +185,7 @@ type Resource struct {
 	IconFill       *ResourceIconFill `yaml:"IconFill"`
 	Direction      string            `yaml:"Direction"`
 	Preset         string            `yaml:"Preset"`
+	Style          string            `yaml:"Style"`
 	Align          string            `yaml:"Align"`
 	HeaderAlign    string            `yaml:"HeaderAlign"`
 	FillColor      string            `yaml:"FillColor"`
@@ -118,18 +193,46 @@ type Resource struct {
 	TitleColor     string            `yaml:"TitleColor"`
 	TitleFillColor string            `yaml:"TitleFillColor"`
 	Font           string            `yaml:"Font"`
+	FontSize       float64           `yaml:"FontSize"`
+	FontWeight     string            `yaml:"FontWeight"`
+	LineSpacing    *int              `yaml:"LineSpacing"`
+	MaxTitleWidth  int               `yaml:"MaxTitleWidth"`
 	Children       []string          `yaml:"Children"`
 	BorderColor    string            `yaml:"BorderColor"`
 	BorderType     string            `yaml:"BorderType"`
 	BorderChildren []BorderChild     `yaml:"BorderChildren"`
 	SpanResources  []string          `yaml:"SpanResources"`
+	Position       *ResourcePosition `yaml:"Position"`
+	Anchor         string            `yaml:"Anchor"`
+	Tags           []string          `yaml:"Tags"`
 	Options        *ResourceOptions  `yaml:"Options"`
+	Points         []string          `yaml:"Points"` // Resources a note points to
+	Badges         []Badge           `yaml:"Badges"`
+	BorderWidth    int               `yaml:"BorderWidth"`
+	CornerRadius   int               `yaml:"CornerRadius"`
+	Shadow         *bool             `yaml:"Shadow"`
+	Stacked        bool              `yaml:"Stacked"` // Draw the icon as a stack of copies for multiple instances
+	Count          int               `yaml:"Count"`   // Number of instances, drawn as a stack with a count label
+}
+
+// Badge is a counter or status dot drawn over the icon
+type Badge struct {
+	Text     string `yaml:"Text"`     // A badge without text is drawn as a dot
+	Color    string `yaml:"Color"`    // Default: #D13212
+	Position string `yaml:"Position"` // N, NE (default), E, SE, S, SW, W or NW of the icon
+}
+
+type ResourcePosition struct {
+	X int `yaml:"X"`
+	Y int `yaml:"Y"`
 }
 
 type ResourceOptions struct {
 	GroupingOffset          *bool `yaml:"GroupingOffset"`
 	GroupingOffsetDirection *bool `yaml:"GroupingOffsetDirection"`
 	UnorderedChildren       *bool `yaml:"UnorderedChildren"`
+	Collapsed               *bool `yaml:"Collapsed"`
+	Bundling                *bool `yaml:"Bundling"`
 }
 
 type ResourceIconFill struct {
@@ -143,6 +246,7 @@ type BorderChild struct {
 }
 
 type Link struct {
+	Id              string          `yaml:"Id"`
 	Source          string          `yaml:"Source"`
 	SourcePosition  string          `yaml:"SourcePosition"`
 	SourceArrowHead types.ArrowHead `yaml:"SourceArrowHead"`
@@ -153,7 +257,20 @@ type Link struct {
 	LineWidth       int             `yaml:"LineWidth"`
 	LineColor       string          `yaml:"LineColor"`
 	LineStyle       string          `yaml:"LineStyle"`
+	DashPattern     []float64       `yaml:"DashPattern"`
 	Labels          LinkLabels      `yaml:"Labels"`
+	Waypoints       []Waypoint      `yaml:"Waypoints"`
+	Step            int             `yaml:"Step"`
+	Style           string          `yaml:"Style"`
+	Tags            []string        `yaml:"Tags"`
+}
+
+// Waypoint is either an absolute point (X, Y) or a position on a resource (Via, Side)
+type Waypoint struct {
+	X    int    `yaml:"X"`
+	Y    int    `yaml:"Y"`
+	Via  string `yaml:"Via"`
+	Side string `yaml:"Side"`
 }
 
 type LinkLabels struct {
@@ -166,10 +283,15 @@ type LinkLabels struct {
 }
 
 type LinkLabel struct {
-	Type  *string `yaml:"Type"`
-	Title string  `yaml:"Title"`
-	Color *string `yaml:"Color"`
-	Font  *string `yaml:"Font"`
+	Type        *string `yaml:"Type"`
+	Title       string  `yaml:"Title"`
+	Color       *string `yaml:"Color"`
+	FillColor   *string `yaml:"FillColor"`
+	Font        *string `yaml:"Font"`
+	FontSize    float64 `yaml:"FontSize"`
+	FontWeight  string  `yaml:"FontWeight"`
+	LineSpacing int     `yaml:"LineSpacing"`
+	MaxWidth    int     `yaml:"MaxWidth"`
 }
 
 type CreateOptions struct {
@@ -180,9 +302,18 @@ type CreateOptions struct {
 	OverrideFont              string
 	Width                     int
 	Height                    int
+	Collapse                  []string // Resource names to collapse into a single box
+	MaxDepth                  int      // Collapse groups nested deeper than this depth from Canvas (0 means no limit)
+	View                      string   // Name of the view in the Views section to render
+	IncludeTags               []string // Render only resources and links with any of these tags
+	ExcludeTags               []string // Do not render resources and links with any of these tags
+	ThemeFile                 string   // Theme file, overridden by the Theme section
+	Dark                      bool     // Render for a dark background
+	FontFallbacks             []string // Fonts drawing characters missing from the title fonts, in order
+	FontDirs                  []string // Directories searched for fonts given by file name
 }
 
-func createDiagram(resources map[string]*types.Resource, outputfile *string, opts *CreateOptions) error {
+func createDiagram(template *TemplateStruct, resources map[string]*types.Resource, legend *types.Legend, titleBlock *types.TitleBlock, outputfile *string, opts *CreateOptions) error {
 
 	// Check for file overwrite before processing
 	if err := CheckOutputFileOverwrite(*outputfile, opts.OverwriteMode); err != nil {
@@ -208,6 +339,9 @@ func createDiagram(resources map[string]*types.Resource, outputfile *string, opt
 	if err := canvas.ZeroAdjust(); err != nil {
 		return fmt.Errorf("error adjusting diagram: %w", err)
 	}
+	if err := canvas.ResolvePinnedPositions(); err != nil {
+		return fmt.Errorf("error placing pinned resources: %w", err)
+	}
 
 	// Resolve auto-positions after layout is complete
 	for _, resource := range resources {
@@ -218,8 +352,9 @@ func createDiagram(resources map[string]*types.Resource, outputfile *string, opt
 		}
 	}
 
-	// Reset convergence point tracking before drawing
+	// Reset convergence point and link crossing tracking before drawing
 	types.ResetConvergencePointSegments()
+	types.ResetDrawnLinkSegments()
 
 	img, err := canvas.Draw(nil, nil)
 	if err != nil {
@@ -243,6 +378,44 @@ func createDiagram(resources map[string]*types.Resource, outputfile *string, opt
 		}
 	}
 
+	// Mark where bundled links join and leave their shared lines
+	types.DrawBundleJunctions(img)
+
+	// Draw links ending on links after the links they end on
+	for _, link := range collectLinks(resources) {
+		if link.IsJunction() {
+			if err := link.DrawJunction(img); err != nil {
+				return fmt.Errorf("error drawing junction link: %w", err)
+			}
+		}
+	}
+
+	// Draw step badges on top of every link and overlay
+	links := collectLinks(resources)
+	for _, link := range links {
+		if err := link.DrawStep(img, canvas.GetLabelFont()); err != nil {
+			return fmt.Errorf("error drawing link step: %w", err)
+		}
+	}
+	if template.Steps != nil && template.Steps.Legend {
+		img, err = types.DrawStepLegend(img, links, canvas.GetFillColor(), canvas.GetLabelFont())
+		if err != nil {
+			return fmt.Errorf("error drawing step legend: %w", err)
+		}
+	}
+	if legend != nil {
+		img, err = legend.Draw(img, canvas.GetFillColor(), canvas.GetLabelFont())
+		if err != nil {
+			return fmt.Errorf("error drawing legend: %w", err)
+		}
+	}
+	if titleBlock != nil {
+		img, err = titleBlock.Draw(img, canvas.GetFillColor(), canvas.GetLabelFont())
+		if err != nil {
+			return fmt.Errorf("error drawing title block: %w", err)
+		}
+	}
+
 	// Resize the image if width or height is specified
 	if opts != nil && (opts.Width > 0 || opts.Height > 0) {
 		log.Infof("Resizing image to width: %d, height: %d", opts.Width, opts.Height)
@@ -260,12 +433,29 @@ func createDiagram(resources map[string]*types.Resource, outputfile *string, opt
 			log.Warnf("Failed to close output file: %v", closeErr)
 		}
 	}()
-	if err := png.Encode(f, img); err != nil {
+	if err := encodePNG(f, img, pngTexts(template.Metadata)); err != nil {
 		return fmt.Errorf("error encoding PNG: %w", err)
 	}
 	return nil
 }
 
+// collectLinks returns every link once, in a deterministic order
+func collectLinks(resources map[string]*types.Resource) []*types.Link {
+	names := maps.Keys(resources)
+	sort.Strings(names)
+	seen := make(map[*types.Link]bool)
+	links := []*types.Link{}
+	for _, name := range names {
+		for _, link := range append(resources[name].GetLinks(), resources[name].GetJunctionLinks()...) {
+			if !seen[link] {
+				seen[link] = true
+				links = append(links, link)
+			}
+		}
+	}
+	return links
+}
+
 // resizeImage resizes the image while maintaining aspect ratio
 func resizeImage(src *image.RGBA, width, height int) *image.RGBA {
 	// Get original dimensions
@@ -377,13 +567,19 @@ func loadResources(template *TemplateStruct, ds definition.DefinitionStructure,
 				return fmt.Errorf("Canvas resource %s not found in resources map", k)
 			}
 			resource.SetBorderColor(color.RGBA{0, 0, 0, 0})
-			resource.SetFillColor(color.RGBA{255, 255, 255, 255})
+			if darkMode {
+				resource.SetFillColor(types.DarkBackgroundColor)
+			} else {
+				resource.SetFillColor(color.RGBA{255, 255, 255, 255})
+			}
 		case "AWS::Diagram::Resource":
 			resources[k] = new(types.Resource).Init()
 		case "AWS::Diagram::VerticalStack":
 			resources[k] = new(types.VerticalStack).Init()
 		case "AWS::Diagram::HorizontalStack":
 			resources[k] = new(types.HorizontalStack).Init()
+		case "AWS::Diagram::Note":
+			resources[k] = new(types.Note).Init()
 		default:
 			def, ok := ds.Definitions[v.Type]
 			if !ok {
@@ -407,7 +603,7 @@ func loadResources(template *TemplateStruct, ds definition.DefinitionStructure,
 				resources[k] = new(types.Resource).Init()
 			}
 			if fill := def.Fill; fill != nil {
-				fillColor, err := stringToColor(fill.Color)
+				fillColor, err := definitionColor(fill.Color, true)
 				if err != nil {
 					return fmt.Errorf("failed to parse fill color for resource %s: %w", k, err)
 				}
@@ -418,7 +614,7 @@ func loadResources(template *TemplateStruct, ds definition.DefinitionStructure,
 				resource.SetFillColor(fillColor)
 			}
 			if border := def.Border; border != nil {
-				borderColor, err := stringToColor(border.Color)
+				borderColor, err := definitionColor(border.Color, false)
 				if err != nil {
 					return fmt.Errorf("failed to parse border color for resource %s: %w", k, err)
 				}
@@ -435,6 +631,9 @@ func loadResources(template *TemplateStruct, ds definition.DefinitionStructure,
 				default:
 					resource.SetBorderType(types.BORDER_TYPE_STRAIGHT)
 				}
+				if err := setDefinitionBorderStyle(resource, border); err != nil {
+					return fmt.Errorf("failed to set border of resource %s: %w", k, err)
+				}
 			}
 			if label := def.Label; label != nil {
 				resource, exists := resources[k]
@@ -445,7 +644,7 @@ func loadResources(template *TemplateStruct, ds definition.DefinitionStructure,
 					resource.SetLabel(&label.Title, nil, nil)
 				}
 				if label.Color != "" {
-					c, err := stringToColor(label.Color)
+					c, err := definitionColor(label.Color, false)
 					if err != nil {
 						return fmt.Errorf("failed to parse label color for resource %s: %w", k, err)
 					}
@@ -463,7 +662,7 @@ func loadResources(template *TemplateStruct, ds definition.DefinitionStructure,
 					resource.SetLabel(nil, nil, &label.Font)
 				}
 				if label.FillColor != "" {
-					c, err := stringToColor(label.FillColor)
+					c, err := definitionColor(label.FillColor, true)
 					if err != nil {
 						return fmt.Errorf("failed to parse label fill color for resource %s: %w", k, err)
 					}
@@ -485,13 +684,19 @@ func loadResources(template *TemplateStruct, ds definition.DefinitionStructure,
 				if !exists {
 					return fmt.Errorf("resource %s not found when loading icon", k)
 				}
-				err := resource.LoadIcon(def.CacheFilePath)
+				err := resource.LoadIcon(definitionIconPath(def))
 				if err != nil {
 					return fmt.Errorf("failed to load icon from cache file path: %w", err)
 				}
 			}
 		}
 
+		if resource, exists := resources[k]; exists {
+			if err := applyTheme(template.Theme, resource, k, v); err != nil {
+				return err
+			}
+		}
+
 		switch v.Preset {
 		case "BlankGroup":
 			resource, exists := resources[k]
@@ -521,14 +726,14 @@ func loadResources(template *TemplateStruct, ds definition.DefinitionStructure,
 					return fmt.Errorf("resource %s not found for preset configuration", k)
 				}
 				if fill := def.Fill; fill != nil {
-					fillColor, err := stringToColor(fill.Color)
+					fillColor, err := definitionColor(fill.Color, true)
 					if err != nil {
 						return fmt.Errorf("failed to parse fill color for resource %s: %w", k, err)
 					}
 					resource.SetFillColor(fillColor)
 				}
 				if border := def.Border; border != nil {
-					borderColor, err := stringToColor(border.Color)
+					borderColor, err := definitionColor(border.Color, false)
 					if err != nil {
 						return fmt.Errorf("failed to parse border color for resource %s: %w", k, err)
 					}
@@ -541,13 +746,16 @@ func loadResources(template *TemplateStruct, ds definition.DefinitionStructure,
 					default:
 						resource.SetBorderType(types.BORDER_TYPE_STRAIGHT)
 					}
+					if err := setDefinitionBorderStyle(resource, border); err != nil {
+						return fmt.Errorf("failed to set border of resource %s: %w", k, err)
+					}
 				}
 				if label := def.Label; label != nil {
 					if label.Title != "" {
 						resource.SetLabel(&label.Title, nil, nil)
 					}
 					if label.Color != "" {
-						c, err := stringToColor(label.Color)
+						c, err := definitionColor(label.Color, false)
 						if err != nil {
 							return fmt.Errorf("failed to parse label color for resource %s: %w", k, err)
 						}
@@ -557,7 +765,7 @@ func loadResources(template *TemplateStruct, ds definition.DefinitionStructure,
 						resource.SetLabel(nil, nil, &label.Font)
 					}
 					if label.FillColor != "" {
-						c, err := stringToColor(label.FillColor)
+						c, err := definitionColor(label.FillColor, true)
 						if err != nil {
 							return fmt.Errorf("failed to parse label fill color for resource %s: %w", k, err)
 						}
@@ -569,7 +777,7 @@ func loadResources(template *TemplateStruct, ds definition.DefinitionStructure,
 				}
 				if icon := def.Icon; icon != nil {
 					if def.CacheFilePath != "" {
-						err := resource.LoadIcon(def.CacheFilePath)
+						err := resource.LoadIcon(definitionIconPath(def))
 						if err != nil {
 							return fmt.Errorf("failed to load icon from cache file path: %w", err)
 						}
@@ -652,6 +860,73 @@ func loadResources(template *TemplateStruct, ds definition.DefinitionStructure,
 			}
 			resource.SetLabel(nil, nil, &v.Font)
 		}
+		if v.FontSize != 0 || v.FontWeight != "" || v.LineSpacing != nil || v.MaxTitleWidth != 0 {
+			resource, exists := resources[k]
+			if !exists {
+				return fmt.Errorf("resource %s not found for title text options", k)
+			}
+			if v.FontSize < 0 || v.MaxTitleWidth < 0 {
+				return fmt.Errorf("font size and max title width of resource %s must not be negative", k)
+			}
+			if v.FontSize != 0 {
+				resource.SetFontSize(v.FontSize)
+			}
+			if v.FontWeight != "" {
+				if err := resource.SetFontWeight(v.FontWeight); err != nil {
+					return fmt.Errorf("failed to set font weight for resource %s: %w", k, err)
+				}
+			}
+			if v.LineSpacing != nil {
+				resource.SetLineSpacing(*v.LineSpacing)
+			}
+			if v.MaxTitleWidth != 0 {
+				resource.SetMaxTitleWidth(v.MaxTitleWidth)
+			}
+		}
+		if v.BorderWidth != 0 || v.CornerRadius != 0 || v.Shadow != nil {
+			resource, exists := resources[k]
+			if !exists {
+				return fmt.Errorf("resource %s not found for border style", k)
+			}
+			if v.BorderWidth != 0 {
+				if err := resource.SetBorderWidth(v.BorderWidth); err != nil {
+					return fmt.Errorf("failed to set border width for resource %s: %w", k, err)
+				}
+			}
+			if v.CornerRadius != 0 {
+				if err := resource.SetCornerRadius(v.CornerRadius); err != nil {
+					return fmt.Errorf("failed to set corner radius for resource %s: %w", k, err)
+				}
+			}
+			if v.Shadow != nil {
+				resource.SetShadow(*v.Shadow)
+			}
+		}
+		if v.Stacked || v.Count != 0 {
+			resource, exists := resources[k]
+			if !exists {
+				return fmt.Errorf("resource %s not found for stacked icon", k)
+			}
+			resource.SetStacked(v.Stacked)
+			if v.Count != 0 {
+				if err := resource.SetCount(v.Count); err != nil {
+					return fmt.Errorf("failed to set count for resource %s: %w", k, err)
+				}
+			}
+		}
+		if len(v.Badges) > 0 {
+			resource, exists := resources[k]
+			if !exists {
+				return fmt.Errorf("resource %s not found for badges", k)
+			}
+			for _, b := range v.Badges {
+				badge, err := convertBadge(b)
+				if err != nil {
+					return fmt.Errorf("failed to load badge of resource %s: %w", k, err)
+				}
+				resource.AddBadge(badge)
+			}
+		}
 		if v.Align != "" {
 			resource, exists := resources[k]
 			if !exists {
@@ -703,6 +978,29 @@ func loadResources(template *TemplateStruct, ds definition.DefinitionStructure,
 			}
 		}
 
+		if v.Position != nil || v.Anchor != "" {
+			resource, exists := resources[k]
+			if !exists {
+				return fmt.Errorf("resource %s not found for position", k)
+			}
+			anchor := types.WINDROSE_NW
+			if v.Anchor != "" {
+				var err error
+				anchor, err = types.ConvertWindrose(v.Anchor)
+				if err != nil {
+					return fmt.Errorf("failed to convert anchor for resource %s: %w", k, err)
+				}
+				if anchor == types.WINDROSE_AUTO {
+					return fmt.Errorf("anchor 'auto' is not supported for resource %s", k)
+				}
+			}
+			offset := image.Point{}
+			if v.Position != nil {
+				offset = image.Point{X: v.Position.X, Y: v.Position.Y}
+			}
+			resource.SetPin(anchor, offset)
+		}
+
 		// Process Options
 		if v.Options != nil {
 			resource, exists := resources[k]
@@ -718,6 +1016,9 @@ func loadResources(template *TemplateStruct, ds definition.DefinitionStructure,
 			if v.Options.UnorderedChildren != nil {
 				resource.SetUnorderedChildren(*v.Options.UnorderedChildren)
 			}
+			if v.Options.Bundling != nil {
+				resource.SetBundling(*v.Options.Bundling)
+			}
 		}
 	}
 
@@ -739,7 +1040,12 @@ func associateChildren(template *TemplateStruct, resources map[string]*types.Res
 		if !ok {
 			return fmt.Errorf("unknown resource %s", logicalId)
 		}
-		for _, child := range v.Children {
+		children := v.Children
+		if resource.IsNote() && len(children) > 0 {
+			log.Warnf("Children of note %s are ignored", logicalId)
+			children = nil
+		}
+		for _, child := range children {
 			childResource, ok := resources[child]
 			if ok {
 				log.Infof("Add child(%s) on %s", child, logicalId)
@@ -785,6 +1091,49 @@ func associateChildren(template *TemplateStruct, resources map[string]*types.Res
 	return nil
 }
 
+// collapseResources hides the descendants of groups marked with Options.Collapsed,
+// listed in opts.Collapse, or located at opts.MaxDepth from the Canvas.
+// It must be called after associateChildren and before loadLinks.
+func collapseResources(template *TemplateStruct, resources map[string]*types.Resource, opts *CreateOptions) {
+	marked := make(map[*types.Resource]bool)
+	for name, v := range template.Resources {
+		if v.Options != nil && v.Options.Collapsed != nil && *v.Options.Collapsed {
+			if resource, ok := resources[name]; ok {
+				marked[resource] = true
+			}
+		}
+	}
+	maxDepth := 0
+	if opts != nil {
+		for _, name := range opts.Collapse {
+			resource, ok := resources[name]
+			if !ok {
+				log.Warnf("Resource `%s` to collapse was not found, ignoring it.", name)
+				continue
+			}
+			marked[resource] = true
+		}
+		maxDepth = opts.MaxDepth
+	}
+
+	// Collapse inner groups first so that outer groups count their hidden resources
+	var walk func(r *types.Resource, depth int)
+	walk = func(r *types.Resource, depth int) {
+		for _, child := range r.GetChildren() {
+			walk(child, depth+1)
+		}
+		if maxDepth > 0 && depth == maxDepth {
+			marked[r] = true
+		}
+		if marked[r] {
+			r.Collapse()
+		}
+	}
+	if canvas, ok := resources["Canvas"]; ok {
+		walk(canvas, 0)
+	}
+}
+
 // checkUnusedResources warns about resources that are defined but not used in the diagram
 func checkUnusedResources(template *TemplateStruct) {
 	// Track which resources are referenced
@@ -836,6 +1185,8 @@ func convertLabel(label *LinkLabel) (*types.LinkLabel, error) {
 		switch *label.Type {
 		case "horizontal":
 			r.Type = types.LINK_LABEL_TYPE_HORIZONTAL
+		case "along-path":
+			r.Type = types.LINK_LABEL_TYPE_ALONG_PATH
 		default:
 			r.Type = types.LINK_LABEL_TYPE_HORIZONTAL
 		}
@@ -850,28 +1201,135 @@ func convertLabel(label *LinkLabel) (*types.LinkLabel, error) {
 		}
 		r.Color = &c
 	}
+	if label.FillColor != nil {
+		c, err := stringToColor(*label.FillColor)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse label fill color: %w", err)
+		}
+		r.FillColor = &c
+	}
 	if label.Font != nil {
 		r.Font = *label.Font
 	}
+	if label.FontSize < 0 || label.MaxWidth < 0 {
+		return nil, fmt.Errorf("font size and max width of label must not be negative")
+	}
+	if label.FontWeight != "" && label.FontWeight != types.FONT_WEIGHT_NORMAL && label.FontWeight != types.FONT_WEIGHT_BOLD {
+		return nil, fmt.Errorf("unknown label font weight %s (allowed: normal, bold)", label.FontWeight)
+	}
+	r.FontSize = label.FontSize
+	r.FontWeight = label.FontWeight
+	r.LineSpacing = label.LineSpacing
+	r.MaxWidth = label.MaxWidth
 	return r, nil
 }
 
+// linkLineColor returns the line color of a link: the color set on the link, or the line color
+// of the theme, or black (lightened in dark mode)
+func linkLineColor(template *TemplateStruct, c string) (color.RGBA, error) {
+	if c != "" {
+		lineColor, err := stringToColor(c)
+		if err != nil {
+			return color.RGBA{}, fmt.Errorf("failed to parse line color: %w", err)
+		}
+		return lineColor, nil
+	}
+	if template.Theme != nil && template.Theme.LineColor != "" {
+		lineColor, err := stringToColor(template.Theme.LineColor)
+		if err != nil {
+			return color.RGBA{}, fmt.Errorf("failed to parse theme line color: %w", err)
+		}
+		return lineColor, nil
+	}
+	lineColor := color.RGBA{0, 0, 0, 255}
+	if darkMode {
+		lineColor = types.DarkStrokeColor(lineColor)
+	}
+	return lineColor, nil
+}
+
 func loadLinks(template *TemplateStruct, resources map[string]*types.Resource) error {
 
+	// Track source/target pairs to de-duplicate links re-targeted to collapsed groups
+	linked := make(map[[2]*types.Resource]*types.Link)
+
+	// Last step number, for auto-numbering
+	step := 0
+
+	// Links with an Id, and the Id of the link each junction link ends on.
+	// Ids of links that are not drawn are registered with a nil link.
+	linksById := make(map[string]*types.Link)
+	junctionTargets := make(map[*types.Link]string)
+	junctionLinks := []*types.Link{}
+	registerId := func(id string, link *types.Link) error {
+		if id == "" {
+			return nil
+		}
+		if _, exists := linksById[id]; exists {
+			return fmt.Errorf("duplicated link Id %s", id)
+		}
+		linksById[id] = link
+		return nil
+	}
+
 	for _, v := range template.Links {
 		sourceResource, ok := resources[v.Source]
 		if !ok {
 			log.Warnf("Not found Source esource %s", v.Source)
+			if err := registerId(v.Id, nil); err != nil {
+				return err
+			}
 			continue
 		}
 		source := sourceResource
 
-		targetResource, ok := resources[v.Target]
-		if !ok {
-			log.Warnf("Not found Target resource %s", v.Target)
-			continue
+		// "link:<Id>" ends the link on another link instead of a resource
+		targetLinkId, isJunction := strings.CutPrefix(v.Target, "link:")
+		var target *types.Resource
+		if !isJunction {
+			targetResource, ok := resources[v.Target]
+			if !ok {
+				log.Warnf("Not found Target resource %s", v.Target)
+				if err := registerId(v.Id, nil); err != nil {
+					return err
+				}
+				continue
+			}
+			target = targetResource
+		}
+
+		// Re-target links whose endpoints are hidden in a collapsed group.
+		// The original position refers to the hidden resource, so fall back to auto-positioning.
+		retargeted := false
+		if collapsed := source.CollapsedAncestor(); collapsed != nil {
+			source = collapsed
+			v.SourcePosition = ""
+			retargeted = true
+		}
+		if !isJunction {
+			if collapsed := target.CollapsedAncestor(); collapsed != nil {
+				target = collapsed
+				v.TargetPosition = ""
+				retargeted = true
+			}
+		}
+		if retargeted && !isJunction {
+			if source == target {
+				log.Infof("Skip link(%s-%s) inside a collapsed group", v.Source, v.Target)
+				if err := registerId(v.Id, nil); err != nil {
+					return err
+				}
+				continue
+			}
+			// Links ending on the skipped link end on the link replacing it
+			if existing := linked[[2]*types.Resource{source, target}]; existing != nil {
+				log.Infof("Skip duplicated link(%s-%s) to a collapsed group", v.Source, v.Target)
+				if err := registerId(v.Id, existing); err != nil {
+					return err
+				}
+				continue
+			}
 		}
-		target := targetResource
 
 		log.Infof("Add link(%s-%s)", v.Source, v.Target)
 		lineWidth := v.LineWidth
@@ -879,28 +1337,37 @@ func loadLinks(template *TemplateStruct, resources map[string]*types.Resource) e
 			lineWidth = 2
 		}
 
-		lineColor := color.RGBA{0, 0, 0, 255}
-		if v.LineColor != "" {
-			var err error
-			lineColor, err = stringToColor(v.LineColor)
-			if err != nil {
-				return fmt.Errorf("failed to parse line color: %w", err)
-			}
+		lineColor, err := linkLineColor(template, v.LineColor)
+		if err != nil {
+			return err
 		}
 
 		// Convert positions (empty string and "auto" both become WINDROSE_AUTO)
-		sourcePosition, err := types.ConvertWindrose(v.SourcePosition)
+		sourcePosition, err := types.ConvertLinkPosition(v.SourcePosition)
 		if err != nil {
 			return fmt.Errorf("failed to convert source windrose position: %w", err)
 		}
-		targetPosition, err := types.ConvertWindrose(v.TargetPosition)
+		targetPosition, err := types.ConvertLinkPosition(v.TargetPosition)
 		if err != nil {
 			return fmt.Errorf("failed to convert target windrose position: %w", err)
 		}
 
+		if isJunction {
+			if v.Type != "" && v.Type != "straight" && v.Type != "orthogonal" {
+				log.Warnf("Link(%s-%s) is drawn as a straight link: links ending on a link support straight and orthogonal types", v.Source, v.Target)
+			}
+			if v.TargetPosition != "" {
+				log.Warnf("TargetPosition of link(%s-%s) is ignored: the link ends at the nearest point of the target link", v.Source, v.Target)
+			}
+			targetPosition = types.WINDROSE_AUTO
+		}
+
 		link := new(types.Link).Init(source, sourcePosition, v.SourceArrowHead, target, targetPosition, v.TargetArrowHead, lineWidth, lineColor)
 		link.SetType(v.Type)
 		link.SetLineStyle(v.LineStyle)
+		if err := link.SetDashPattern(v.DashPattern); err != nil {
+			return fmt.Errorf("failed to set dash pattern of link(%s-%s): %w", v.Source, v.Target, err)
+		}
 		if v.Labels.SourceRight != nil {
 			label, err := convertLabel(v.Labels.SourceRight)
 			if err != nil {
@@ -943,12 +1410,121 @@ func loadLinks(template *TemplateStruct, resources map[string]*types.Resource) e
 			}
 			link.Labels.AutoLeft = label
 		}
+		if template.Theme != nil && template.Theme.LabelColor != "" {
+			c, err := stringToColor(template.Theme.LabelColor)
+			if err != nil {
+				return fmt.Errorf("failed to parse theme label color: %w", err)
+			}
+			for _, label := range []*types.LinkLabel{
+				link.Labels.SourceRight, link.Labels.SourceLeft,
+				link.Labels.TargetRight, link.Labels.TargetLeft,
+				link.Labels.AutoRight, link.Labels.AutoLeft,
+			} {
+				if label != nil && label.Color == nil {
+					label.Color = &c
+				}
+			}
+		}
+		if len(v.Waypoints) > 0 {
+			if v.Type != "orthogonal" && v.Type != "orthogonal-routed" {
+				log.Warnf("Waypoints of link(%s-%s) are ignored: only orthogonal links support waypoints", v.Source, v.Target)
+			}
+			waypoints, err := convertWaypoints(v.Waypoints, resources)
+			if err != nil {
+				return fmt.Errorf("failed to convert waypoints of link(%s-%s): %w", v.Source, v.Target, err)
+			}
+			link.SetWaypoints(waypoints)
+		}
+		// An explicit Step restarts auto-numbering from its value
+		autoNumber := template.Steps != nil && template.Steps.AutoNumber
+		if v.Step > 0 || autoNumber {
+			if v.Step > 0 {
+				step = v.Step
+			} else {
+				step++
+			}
+			position := ""
+			if template.Steps != nil {
+				position = template.Steps.Position
+			}
+			if err := link.SetStep(step, position); err != nil {
+				return fmt.Errorf("failed to set step of link(%s-%s): %w", v.Source, v.Target, err)
+			}
+		}
+		if err := registerId(v.Id, link); err != nil {
+			return err
+		}
+		if isJunction {
+			junctionTargets[link] = targetLinkId
+			junctionLinks = append(junctionLinks, link)
+			continue
+		}
+		linked[[2]*types.Resource{source, target}] = link
 		source.AddLink(link)
 		target.AddLink(link)
 	}
+
+	// Junction links are attached once every link Id is known
+	for _, link := range junctionLinks {
+		targetLink, ok := linksById[junctionTargets[link]]
+		if !ok {
+			return fmt.Errorf("not found Target link %s", junctionTargets[link])
+		}
+		if targetLink == nil {
+			log.Infof("Skip link ending on link %s, which is not drawn", junctionTargets[link])
+			continue
+		}
+		link.SetTargetLink(targetLink)
+	}
+	for _, link := range junctionLinks {
+		if !link.IsJunction() {
+			continue
+		}
+		visited := map[*types.Link]bool{}
+		for l := link; l.IsJunction(); l = l.TargetLink {
+			if visited[l] {
+				return fmt.Errorf("links ending on links form a cycle at link %s", junctionTargets[link])
+			}
+			visited[l] = true
+		}
+		link.Source.AddJunctionLink(link)
+	}
 	return nil
 }
 
+func convertWaypoints(waypoints []Waypoint, resources map[string]*types.Resource) ([]types.Waypoint, error) {
+	result := []types.Waypoint{}
+	for _, w := range waypoints {
+		if w.Via == "" {
+			if w.Side != "" {
+				return nil, fmt.Errorf("waypoint Side %s requires Via", w.Side)
+			}
+			result = append(result, types.Waypoint{Point: image.Point{w.X, w.Y}})
+			continue
+		}
+		resource, ok := resources[w.Via]
+		if !ok {
+			log.Warnf("Not found waypoint resource %s", w.Via)
+			continue
+		}
+		if collapsed := resource.CollapsedAncestor(); collapsed != nil {
+			resource = collapsed
+		}
+		if w.Side == "" {
+			return nil, fmt.Errorf("waypoint via %s requires Side", w.Via)
+		}
+		position, err := types.ConvertWindrose(w.Side)
+		if err != nil {
+			return nil, fmt.Errorf("failed to convert waypoint side: %w", err)
+		}
+		if position == types.WINDROSE_AUTO {
+			return nil, fmt.Errorf("waypoint via %s does not support auto Side", w.Via)
+		}
+		result = append(result, types.Waypoint{Resource: resource, Position: position})
+	}
+	return result, nil
+}
+
 func IsURL(str string) bool {
 	if strings.HasPrefix(str, "http://") || strings.HasPrefix(str, "https://") {
 		return true
@@ -5,6 +5,7 @@ package ctl
 
 import (
 	"image"
+	"image/color"
 	"reflect"
 	"testing"
 
@@ -330,3 +331,293 @@ func TestIsAllowedDefinitionURL(t *testing.T) {
 		})
 	}
 }
+
+func TestLoadLinksRetargetsCollapsedGroups(t *testing.T) {
+	resources := map[string]*types.Resource{
+		"Canvas": new(types.Resource).Init(),
+		"User":   new(types.Resource).Init(),
+		"Group":  new(types.Resource).Init(),
+		"DB1":    new(types.Resource).Init(),
+		"DB2":    new(types.Resource).Init(),
+		"Admin":  new(types.Resource).Init(),
+	}
+	for _, child := range []string{"User", "Group", "Admin"} {
+		if err := resources["Canvas"].AddChild(resources[child]); err != nil {
+			t.Fatalf("AddChild failed: %v", err)
+		}
+	}
+	for _, child := range []string{"DB1", "DB2"} {
+		if err := resources["Group"].AddChild(resources[child]); err != nil {
+			t.Fatalf("AddChild failed: %v", err)
+		}
+	}
+	template := &TemplateStruct{
+		Diagram: Diagram{
+			Links: []Link{
+				{Source: "User", SourcePosition: "E", Target: "DB1", TargetPosition: "W"},
+				{Id: "write", Source: "User", SourcePosition: "E", Target: "DB2", TargetPosition: "W"},
+				{Id: "replicate", Source: "DB1", Target: "DB2"},
+				{Source: "Admin", Target: "link:write"},
+				{Source: "Admin", Target: "link:replicate"},
+			},
+		},
+	}
+
+	collapseResources(template, resources, &CreateOptions{Collapse: []string{"Group"}})
+	if err := loadLinks(template, resources); err != nil {
+		t.Fatalf("loadLinks failed: %v", err)
+	}
+
+	links := resources["Group"].GetLinks()
+	if len(links) != 1 {
+		t.Fatalf("Expected 1 de-duplicated link to the collapsed group, got %d", len(links))
+	}
+	if links[0].Source != resources["User"] || links[0].Target != resources["Group"] {
+		t.Errorf("Expected link User->Group, got %p->%p", links[0].Source, links[0].Target)
+	}
+	if links[0].TargetPosition != types.WINDROSE_AUTO {
+		t.Errorf("Expected re-targeted position to be auto, got %v", links[0].TargetPosition)
+	}
+	if len(resources["DB1"].GetLinks()) != 0 {
+		t.Errorf("Expected hidden resources to have no links, got %d", len(resources["DB1"].GetLinks()))
+	}
+	// Links ending on a de-duplicated link end on the link replacing it, and links
+	// ending on a link hidden in the group are skipped
+	junctions := resources["Admin"].GetJunctionLinks()
+	if len(junctions) != 1 || junctions[0].TargetLink != links[0] {
+		t.Errorf("Expected 1 link ending on the de-duplicated link, got %d", len(junctions))
+	}
+}
+
+func TestLoadLinksSteps(t *testing.T) {
+	tests := []struct {
+		name     string
+		steps    *Steps
+		explicit []int
+		expected []int
+	}{
+		{"No steps", nil, []int{0, 0, 0}, []int{0, 0, 0}},
+		{"Explicit steps only", nil, []int{2, 0, 1}, []int{2, 0, 1}},
+		{"Auto-numbering", &Steps{AutoNumber: true}, []int{0, 0, 0}, []int{1, 2, 3}},
+		{"Explicit step restarts auto-numbering", &Steps{AutoNumber: true}, []int{0, 5, 0}, []int{1, 5, 6}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resources := map[string]*types.Resource{
+				"A": new(types.Resource).Init(),
+				"B": new(types.Resource).Init(),
+				"C": new(types.Resource).Init(),
+			}
+			template := &TemplateStruct{
+				Diagram: Diagram{
+					Steps: tt.steps,
+					Links: []Link{
+						{Source: "A", Target: "B", Step: tt.explicit[0]},
+						{Source: "B", Target: "C", Step: tt.explicit[1]},
+						{Source: "C", Target: "A", Step: tt.explicit[2]},
+					},
+				},
+			}
+			if err := loadLinks(template, resources); err != nil {
+				t.Fatalf("loadLinks failed: %v", err)
+			}
+			for i, name := range []string{"A", "B", "C"} {
+				for _, link := range resources[name].GetLinks() {
+					if link.Source == resources[name] && link.Step != tt.expected[i] {
+						t.Errorf("Expected step %d for link #%d, got %d", tt.expected[i], i+1, link.Step)
+					}
+				}
+			}
+		})
+	}
+
+	template := &TemplateStruct{
+		Diagram: Diagram{
+			Steps: &Steps{AutoNumber: true, Position: "target"},
+			Links: []Link{{Source: "A", Target: "B"}},
+		},
+	}
+	resources := map[string]*types.Resource{
+		"A": new(types.Resource).Init(),
+		"B": new(types.Resource).Init(),
+	}
+	if err := loadLinks(template, resources); err == nil {
+		t.Error("Expected error for unknown step position")
+	}
+}
+
+func TestLoadLinksJunctions(t *testing.T) {
+	resources := map[string]*types.Resource{
+		"A": new(types.Resource).Init(),
+		"B": new(types.Resource).Init(),
+		"C": new(types.Resource).Init(),
+	}
+	template := &TemplateStruct{
+		Diagram: Diagram{
+			Links: []Link{
+				{Source: "C", Target: "link:bus"},
+				{Id: "bus", Source: "A", Target: "B"},
+			},
+		},
+	}
+	if err := loadLinks(template, resources); err != nil {
+		t.Fatalf("loadLinks failed: %v", err)
+	}
+	junctions := resources["C"].GetJunctionLinks()
+	if len(junctions) != 1 {
+		t.Fatalf("Expected 1 junction link, got %d", len(junctions))
+	}
+	if junctions[0].TargetLink != resources["A"].GetLinks()[0] || junctions[0].Target != nil {
+		t.Error("Expected the junction link to end on the bus link")
+	}
+	if len(resources["C"].GetLinks()) != 0 {
+		t.Error("Expected junction links not to be added as resource links")
+	}
+	if len(collectLinks(resources)) != 2 {
+		t.Errorf("Expected collectLinks to return the bus and the junction link, got %d links", len(collectLinks(resources)))
+	}
+
+	errorCases := []struct {
+		name  string
+		links []Link
+	}{
+		{"Duplicated Id", []Link{{Id: "bus", Source: "A", Target: "B"}, {Id: "bus", Source: "B", Target: "C"}}},
+		{"Cycle", []Link{{Id: "x", Source: "A", Target: "link:y"}, {Id: "y", Source: "B", Target: "link:x"}}},
+		{"Unknown target link", []Link{{Id: "bus", Source: "A", Target: "B"}, {Source: "C", Target: "link:unknown"}}},
+	}
+	for _, tt := range errorCases {
+		t.Run(tt.name, func(t *testing.T) {
+			resources := map[string]*types.Resource{
+				"A": new(types.Resource).Init(),
+				"B": new(types.Resource).Init(),
+				"C": new(types.Resource).Init(),
+			}
+			template := &TemplateStruct{Diagram: Diagram{Links: tt.links}}
+			if err := loadLinks(template, resources); err == nil {
+				t.Error("Expected error")
+			}
+		})
+	}
+}
+
+func TestCollapseResourcesMaxDepth(t *testing.T) {
+	resources := map[string]*types.Resource{
+		"Canvas": new(types.Resource).Init(),
+		"VPC":    new(types.Resource).Init(),
+		"Subnet": new(types.Resource).Init(),
+		"EC2":    new(types.Resource).Init(),
+	}
+	if err := resources["Canvas"].AddChild(resources["VPC"]); err != nil {
+		t.Fatalf("AddChild failed: %v", err)
+	}
+	if err := resources["VPC"].AddChild(resources["Subnet"]); err != nil {
+		t.Fatalf("AddChild failed: %v", err)
+	}
+	if err := resources["Subnet"].AddChild(resources["EC2"]); err != nil {
+		t.Fatalf("AddChild failed: %v", err)
+	}
+
+	collapseResources(&TemplateStruct{}, resources, &CreateOptions{MaxDepth: 1})
+
+	if !resources["VPC"].IsCollapsed() {
+		t.Error("Expected VPC at depth 1 to be collapsed")
+	}
+	if resources["Subnet"].IsCollapsed() {
+		t.Error("Expected Subnet to stay expanded (hidden by VPC)")
+	}
+	if resources["EC2"].CollapsedAncestor() != resources["VPC"] {
+		t.Error("Expected EC2 to be hidden by VPC")
+	}
+}
+
+func TestConvertWaypoints(t *testing.T) {
+	resources := map[string]*types.Resource{
+		"Router": new(types.Resource).Init(),
+	}
+
+	waypoints, err := convertWaypoints([]Waypoint{
+		{X: 100, Y: 200},
+		{Via: "Router", Side: "E"},
+		{Via: "Unknown", Side: "E"},
+	}, resources)
+	if err != nil {
+		t.Fatalf("convertWaypoints failed: %v", err)
+	}
+	expected := []types.Waypoint{
+		{Point: image.Point{100, 200}},
+		{Resource: resources["Router"], Position: types.WINDROSE_E},
+	}
+	if !reflect.DeepEqual(waypoints, expected) {
+		t.Errorf("Expected waypoints %+v, got %+v", expected, waypoints)
+	}
+
+	invalid := []Waypoint{
+		{Via: "Router"},
+		{Via: "Router", Side: "auto"},
+		{Via: "Router", Side: "X"},
+		{X: 1, Y: 1, Side: "E"},
+	}
+	for _, w := range invalid {
+		if _, err := convertWaypoints([]Waypoint{w}, resources); err == nil {
+			t.Errorf("Expected error for waypoint %+v", w)
+		}
+	}
+}
+
+func TestLoadNotePoints(t *testing.T) {
+	resources := map[string]*types.Resource{
+		"Note": new(types.Note).Init(),
+		"A":    new(types.Resource).Init(),
+		"B":    new(types.Resource).Init(),
+	}
+	template := &TemplateStruct{
+		Diagram: Diagram{
+			Resources: map[string]Resource{
+				"Note": {Type: "AWS::Diagram::Note", Points: []string{"A", "Unknown"}},
+				"A":    {Type: "AWS::Diagram::Resource"},
+				"B":    {Type: "AWS::Diagram::Resource", Points: []string{"A"}},
+			},
+		},
+	}
+	loadNotePoints(template, resources)
+
+	links := resources["Note"].GetLinks()
+	if len(links) != 1 {
+		t.Fatalf("Expected 1 leader from the note, got %d", len(links))
+	}
+	leader := links[0]
+	if leader.Target != resources["A"] || leader.LineStyle != types.LINE_STYLE_DASHED || leader.LineWidth != 1 {
+		t.Errorf("Expected a thin dashed leader to A, got %+v", leader)
+	}
+	if leader.SourcePosition != types.WINDROSE_NEAREST || leader.TargetPosition != types.WINDROSE_NEAREST {
+		t.Error("Expected the leader to be attached to the nearest points of the borders")
+	}
+	if len(resources["A"].GetLinks()) != 1 || len(resources["B"].GetLinks()) != 0 {
+		t.Error("Expected Points of resources other than notes to be ignored")
+	}
+}
+
+func TestConvertBadge(t *testing.T) {
+	tests := []struct {
+		name     string
+		badge    Badge
+		expected types.Badge
+		wantErr  bool
+	}{
+		{"Defaults", Badge{Text: "×3"}, types.Badge{Text: "×3", Color: color.RGBA{209, 50, 18, 255}, Position: types.WINDROSE_NE}, false},
+		{"Dot", Badge{Color: "green", Position: "SW"}, types.Badge{Color: color.RGBA{0, 128, 0, 255}, Position: types.WINDROSE_SW}, false},
+		{"UnknownPosition", Badge{Position: "NNE"}, types.Badge{}, true},
+		{"InvalidColor", Badge{Color: "not-a-color"}, types.Badge{}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			badge, err := convertBadge(tt.badge)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Expected error %v, got %v", tt.wantErr, err)
+			}
+			if !tt.wantErr && badge != tt.expected {
+				t.Errorf("Expected %+v, got %+v", tt.expected, badge)
+			}
+		})
+	}
+}
@@ -105,8 +105,11 @@ func CreateDiagramFromDacFile(inputfile string, outputfile *string, opts *Create
 		return fmt.Errorf("failed to decode YAML: %w", err)
 	}
 
+	if err := validatePages(template.Pages); err != nil {
+		return fmt.Errorf("failed to load pages: %w", err)
+	}
+
 	var ds definition.DefinitionStructure
-	resources := make(map[string]*types.Resource)
 
 	log.Info("Load DefinitionFiles section")
 	if opts.OverrideDefFile != "" {
@@ -137,23 +140,76 @@ func CreateDiagramFromDacFile(inputfile string, outputfile *string, opts *Create
 		}
 	}
 
+	if len(template.Pages) == 0 {
+		return createDiagramFromTemplate(&template, ds, outputfile, opts)
+	}
+	for _, page := range template.Pages {
+		log.Infof("--- Page %s ---", page.Name)
+		t, err := pageTemplate(&template, page)
+		if err != nil {
+			return fmt.Errorf("failed to load pages: %w", err)
+		}
+		pageOutput := pageOutputFile(*outputfile, page.Name)
+		if err := createDiagramFromTemplate(t, ds, &pageOutput, opts); err != nil {
+			return fmt.Errorf("page %s: %w", page.Name, err)
+		}
+	}
+	return nil
+}
+
+// createDiagramFromTemplate renders a decoded template with the loaded definitions
+func createDiagramFromTemplate(template *TemplateStruct, ds definition.DefinitionStructure, outputfile *string, opts *CreateOptions) error {
+	resources := make(map[string]*types.Resource)
+
+	log.Info("Apply tag filter")
+	if err := applyTagFilter(template, opts); err != nil {
+		return fmt.Errorf("failed to apply tag filter: %w", err)
+	}
+
+	if err := types.SetLinkCrossing(template.LinkCrossing); err != nil {
+		return fmt.Errorf("failed to load LinkCrossing: %w", err)
+	}
+
+	log.Info("Load theme")
+	if err := loadTheme(template, opts); err != nil {
+		return fmt.Errorf("failed to load theme: %w", err)
+	}
+
+	log.Info("Apply styles")
+	applyStyles(template)
+
 	log.Info("Load Resources section")
-	if err := loadResources(&template, ds, resources); err != nil {
+	if err := loadResources(template, ds, resources); err != nil {
 		return fmt.Errorf("failed to load resources: %w", err)
 	}
 
 	log.Info("Associate children with parent resources")
-	if err := associateChildren(&template, resources); err != nil {
+	if err := associateChildren(template, resources); err != nil {
 		return fmt.Errorf("failed to associate children: %w", err)
 	}
 
 	// Check for unused resources
-	checkUnusedResources(&template)
+	checkUnusedResources(template)
+
+	log.Info("Collapse groups")
+	collapseResources(template, resources, opts)
 
 	log.Info("Add Links section")
-	if err := loadLinks(&template, resources); err != nil {
+	if err := loadLinks(template, resources); err != nil {
 		return fmt.Errorf("failed to load links: %w", err)
 	}
+	loadNotePoints(template, resources)
+
+	log.Info("Load Legend section")
+	legend, err := loadLegend(template, ds, resources)
+	if err != nil {
+		return fmt.Errorf("failed to load legend: %w", err)
+	}
+	log.Info("Load Metadata section")
+	titleBlock, err := loadTitleBlock(template.Metadata)
+	if err != nil {
+		return fmt.Errorf("failed to load metadata: %w", err)
+	}
 
 	// Reorder children based on links (UnorderedChildren feature)
 	log.Info("Reorder children based on links")
@@ -167,7 +223,7 @@ func CreateDiagramFromDacFile(inputfile string, outputfile *string, opts *Create
 		types.ReorderChildrenByLinks(canvas, allLinks)
 	}
 
-	if err := createDiagram(resources, outputfile, opts); err != nil {
+	if err := createDiagram(template, resources, legend, titleBlock, outputfile, opts); err != nil {
 		return fmt.Errorf("failed to create diagram: %w", err)
 	}
 	return nil
@@ -0,0 +1,310 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package ctl
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/awslabs/diagram-as-code/internal/definition"
+	"github.com/awslabs/diagram-as-code/internal/types"
+	log "github.com/sirupsen/logrus"
+)
+
+// Legend lists samples of link styles, borders and icons with their descriptions
+type Legend struct {
+	Title    *string       `yaml:"Title"`    // Default: Legend. An empty title draws no title.
+	Position string        `yaml:"Position"` // N, NE, NW, S, SE (default), SW, E or W of the diagram
+	Auto     bool          `yaml:"Auto"`     // Add an entry for every distinct link style and group type
+	Entries  []LegendEntry `yaml:"Entries"`
+}
+
+// LegendEntry is a sample and its description. The sample takes the fields of a style.
+type LegendEntry struct {
+	Type        string `yaml:"Type"` // link, border or icon
+	Description string `yaml:"Description"`
+	Icon        string `yaml:"Icon"`  // Resource type whose icon is drawn
+	Style       string `yaml:"Style"` // Style of the Styles section
+	Sample      Style  `yaml:",inline"`
+}
+
+const (
+	LEGEND_ENTRY_LINK   = "link"
+	LEGEND_ENTRY_BORDER = "border"
+	LEGEND_ENTRY_ICON   = "icon"
+)
+
+// Built-in types not listed as group types by the auto mode
+var legendSkippedTypes = map[string]bool{
+	"AWS::Diagram::Canvas":          true,
+	"AWS::Diagram::Resource":        true,
+	"AWS::Diagram::VerticalStack":   true,
+	"AWS::Diagram::HorizontalStack": true,
+	"AWS::Diagram::Note":            true,
+}
+
+// mergeStyle returns the sample with the fields it does not set taken from the style
+func mergeStyle(sample, style Style) Style {
+	sample.FillColor = mergeString(sample.FillColor, style.FillColor)
+	sample.BorderColor = mergeString(sample.BorderColor, style.BorderColor)
+	sample.BorderType = mergeString(sample.BorderType, style.BorderType)
+	if sample.BorderWidth == 0 {
+		sample.BorderWidth = style.BorderWidth
+	}
+	if sample.CornerRadius == 0 {
+		sample.CornerRadius = style.CornerRadius
+	}
+	if sample.Shadow == nil {
+		sample.Shadow = style.Shadow
+	}
+	sample.LineColor = mergeString(sample.LineColor, style.LineColor)
+	sample.LineStyle = mergeString(sample.LineStyle, style.LineStyle)
+	if sample.LineWidth == 0 {
+		sample.LineWidth = style.LineWidth
+	}
+	if len(sample.DashPattern) == 0 {
+		sample.DashPattern = style.DashPattern
+	}
+	if sample.SourceArrowHead == (types.ArrowHead{}) {
+		sample.SourceArrowHead = style.SourceArrowHead
+	}
+	if sample.TargetArrowHead == (types.ArrowHead{}) {
+		sample.TargetArrowHead = style.TargetArrowHead
+	}
+	return sample
+}
+
+// isLinkSample reports whether the sample sets fields of links
+func isLinkSample(s Style) bool {
+	return s.LineColor != "" || s.LineStyle != "" || s.LineWidth != 0 || len(s.DashPattern) > 0 ||
+		s.SourceArrowHead != (types.ArrowHead{}) || s.TargetArrowHead != (types.ArrowHead{})
+}
+
+// legendLink returns a link drawn with the style of the sample, and a key identifying its
+// color, line style and width
+func legendLink(template *TemplateStruct, s Style) (*types.Link, string, error) {
+	lineColor, err := linkLineColor(template, s.LineColor)
+	if err != nil {
+		return nil, "", err
+	}
+	lineWidth := s.LineWidth
+	if lineWidth == 0 {
+		lineWidth = 2
+	}
+	link := new(types.Link).Init(nil, types.WINDROSE_AUTO, s.SourceArrowHead, nil, types.WINDROSE_AUTO, s.TargetArrowHead, lineWidth, lineColor)
+	link.SetLineStyle(s.LineStyle)
+	if err := link.SetDashPattern(s.DashPattern); err != nil {
+		return nil, "", err
+	}
+	lineStyle := s.LineStyle
+	if lineStyle == "" || lineStyle == types.LINE_STYLE_NORMAL {
+		lineStyle = types.LINE_STYLE_SOLID
+	}
+	return link, fmt.Sprintf("%v/%s/%d/%v", lineColor, lineStyle, lineWidth, s.DashPattern), nil
+}
+
+// describeLink returns a description of a link style, such as "Dashed line (crimson)"
+func describeLink(s Style) string {
+	lineStyle := types.LINE_STYLE_SOLID
+	if s.LineStyle != "" && s.LineStyle != types.LINE_STYLE_NORMAL {
+		lineStyle = s.LineStyle
+	}
+	if len(s.DashPattern) > 0 {
+		lineStyle = types.LINE_STYLE_DASHED
+	}
+	description := strings.ToUpper(lineStyle[:1]) + lineStyle[1:] + " line"
+	if s.LineColor != "" {
+		description += fmt.Sprintf(" (%s)", s.LineColor)
+	}
+	return description
+}
+
+// legendBorder returns a resource drawn with the border and fill of the sample
+func legendBorder(s Style) (*types.Resource, error) {
+	resource := new(types.Resource).Init()
+	borderColor, err := stringToColor(mergeString(s.BorderColor, "black"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse border color: %w", err)
+	}
+	resource.SetBorderColor(borderColor)
+	if s.BorderType == "dashed" {
+		resource.SetBorderType(types.BORDER_TYPE_DASHED)
+	}
+	if s.BorderWidth != 0 {
+		if err := resource.SetBorderWidth(s.BorderWidth); err != nil {
+			return nil, err
+		}
+	}
+	if s.CornerRadius != 0 {
+		if err := resource.SetCornerRadius(s.CornerRadius); err != nil {
+			return nil, err
+		}
+	}
+	if s.Shadow != nil {
+		resource.SetShadow(*s.Shadow)
+	}
+	if s.FillColor != "" {
+		fillColor, err := stringToColor(s.FillColor)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse fill color: %w", err)
+		}
+		resource.SetFillColor(fillColor)
+	}
+	return resource, nil
+}
+
+// legendIcon returns a resource drawn with the icon of the resource type
+func legendIcon(ds definition.DefinitionStructure, resourceType string) (*types.Resource, error) {
+	def, ok := ds.Definitions[resourceType]
+	if !ok || def == nil {
+		return nil, fmt.Errorf("type %s is not defined in the DAC definition file", resourceType)
+	}
+	resource := new(types.Resource).Init()
+	if err := resource.LoadIcon(definitionIconPath(def)); err != nil {
+		return nil, fmt.Errorf("failed to load icon of %s: %w", resourceType, err)
+	}
+	return resource, nil
+}
+
+// loadLegend returns the legend of the Legend section, or nil without the section.
+// Auto entries are added after the entries of the section, except the link styles they describe.
+func loadLegend(template *TemplateStruct, ds definition.DefinitionStructure, resources map[string]*types.Resource) (*types.Legend, error) {
+	if template.Legend == nil {
+		return nil, nil
+	}
+	position := template.Legend.Position
+	if position == "" {
+		position = "SE"
+	}
+	switch position {
+	case "N", "NE", "NW", "S", "SE", "SW", "E", "W":
+	default:
+		return nil, fmt.Errorf("unknown legend position %s (allowed: N, NE, NW, S, SE, SW, E, W)", position)
+	}
+	windrose, err := types.ConvertWindrose(position)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert legend position: %w", err)
+	}
+	legend := &types.Legend{Title: "Legend", Position: windrose}
+	if template.Legend.Title != nil {
+		legend.Title = *template.Legend.Title
+	}
+
+	linkKeys := map[string]bool{}
+	for i, e := range template.Legend.Entries {
+		sample := e.Sample
+		if e.Style != "" {
+			style, ok := template.Styles[e.Style]
+			if !ok {
+				log.Warnf("Unknown style %s on legend entry %d", e.Style, i+1)
+			} else {
+				sample = mergeStyle(sample, style)
+			}
+		}
+		entryType := e.Type
+		if entryType == "" {
+			switch {
+			case e.Icon != "":
+				entryType = LEGEND_ENTRY_ICON
+			case isLinkSample(sample):
+				entryType = LEGEND_ENTRY_LINK
+			default:
+				entryType = LEGEND_ENTRY_BORDER
+			}
+		}
+		entry := types.LegendEntry{Description: e.Description}
+		switch entryType {
+		case LEGEND_ENTRY_LINK:
+			link, key, err := legendLink(template, sample)
+			if err != nil {
+				return nil, fmt.Errorf("failed to load legend entry %d: %w", i+1, err)
+			}
+			entry.Link = link
+			linkKeys[key] = true
+		case LEGEND_ENTRY_BORDER:
+			resource, err := legendBorder(sample)
+			if err != nil {
+				return nil, fmt.Errorf("failed to load legend entry %d: %w", i+1, err)
+			}
+			entry.Resource = resource
+		case LEGEND_ENTRY_ICON:
+			resource, err := legendIcon(ds, e.Icon)
+			if err != nil {
+				log.Warnf("Skip legend entry %d: %v", i+1, err)
+				continue
+			}
+			entry.Resource = resource
+		default:
+			return nil, fmt.Errorf("unknown type %s of legend entry %d (allowed: link, border, icon)", entryType, i+1)
+		}
+		legend.Entries = append(legend.Entries, entry)
+	}
+
+	if template.Legend.Auto {
+		entries, err := autoLegendEntries(template, ds, resources, linkKeys)
+		if err != nil {
+			return nil, err
+		}
+		legend.Entries = append(legend.Entries, entries...)
+	}
+	return legend, nil
+}
+
+// autoLegendEntries returns an entry for every distinct link style in the order of the Links
+// section, then for every group type and style. Link styles in linkKeys are skipped.
+func autoLegendEntries(template *TemplateStruct, ds definition.DefinitionStructure, resources map[string]*types.Resource, linkKeys map[string]bool) ([]types.LegendEntry, error) {
+	entries := []types.LegendEntry{}
+	for _, v := range template.Links {
+		sample := Style{
+			LineColor:       v.LineColor,
+			LineWidth:       v.LineWidth,
+			LineStyle:       v.LineStyle,
+			DashPattern:     v.DashPattern,
+			SourceArrowHead: v.SourceArrowHead,
+			TargetArrowHead: v.TargetArrowHead,
+		}
+		link, key, err := legendLink(template, sample)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load legend entry of link(%s-%s): %w", v.Source, v.Target, err)
+		}
+		if linkKeys[key] {
+			continue
+		}
+		linkKeys[key] = true
+		description := v.Style
+		if description == "" {
+			description = describeLink(sample)
+		}
+		entries = append(entries, types.LegendEntry{Description: description, Link: link})
+	}
+
+	names := []string{}
+	for name := range template.Resources {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	groupKeys := map[string]bool{}
+	for _, name := range names {
+		v := template.Resources[name]
+		resource, ok := resources[name]
+		if !ok || len(resource.GetChildren()) == 0 || legendSkippedTypes[v.Type] {
+			continue
+		}
+		key := v.Type + "/" + v.Style
+		if groupKeys[key] {
+			continue
+		}
+		groupKeys[key] = true
+		description := v.Type
+		if def, ok := ds.Definitions[v.Type]; ok && def != nil && def.Label != nil && def.Label.Title != "" {
+			description = def.Label.Title
+		}
+		if v.Style != "" {
+			description += fmt.Sprintf(" (%s)", v.Style)
+		}
+		entries = append(entries, types.LegendEntry{Description: description, Resource: resource})
+	}
+	return entries, nil
+}
@@ -0,0 +1,95 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package ctl
+
+import (
+	"testing"
+
+	"github.com/awslabs/diagram-as-code/internal/definition"
+	"github.com/awslabs/diagram-as-code/internal/types"
+)
+
+func TestLoadLegend(t *testing.T) {
+	ds := definition.DefinitionStructure{
+		Definitions: map[string]*definition.Definition{
+			"AWS::EC2::VPC": {
+				Type:  "Group",
+				Label: &definition.DefinitionLabel{Title: "VPC"},
+			},
+		},
+	}
+	vpc := new(types.Resource).Init()
+	app := new(types.Resource).Init()
+	if err := vpc.AddChild(app); err != nil {
+		t.Fatalf("AddChild failed: %v", err)
+	}
+	resources := map[string]*types.Resource{"VPC": vpc, "App": app, "DB": new(types.Resource).Init()}
+	template := &TemplateStruct{
+		Diagram: Diagram{
+			Styles: map[string]Style{
+				"Replication": {LineColor: "crimson", LineStyle: "dashed"},
+			},
+			Resources: map[string]Resource{
+				"VPC": {Type: "AWS::EC2::VPC", Children: []string{"App"}},
+				"App": {Type: "AWS::Diagram::Resource"},
+				"DB":  {Type: "AWS::Diagram::Resource"},
+			},
+			Links: []Link{
+				{Source: "App", Target: "DB"},
+				{Source: "DB", Target: "App", Style: "Replication", LineColor: "crimson", LineStyle: "dashed"},
+				{Source: "DB", Target: "App", LineColor: "blue", LineStyle: "dashed"},
+			},
+			Legend: &Legend{
+				Auto: true,
+				Entries: []LegendEntry{
+					{Description: "Private subnet", Sample: Style{BorderColor: "blue"}},
+					{Description: "Replication", Style: "Replication"},
+					{Description: "Unknown icon", Icon: "AWS::Unknown::Type"},
+				},
+			},
+		},
+	}
+	legend, err := loadLegend(template, ds, resources)
+	if err != nil {
+		t.Fatalf("loadLegend failed: %v", err)
+	}
+	if legend.Title != "Legend" || legend.Position != types.WINDROSE_SE {
+		t.Errorf("Expected the default title and position, got %q and %d", legend.Title, legend.Position)
+	}
+	descriptions := []string{}
+	for _, e := range legend.Entries {
+		descriptions = append(descriptions, e.Description)
+	}
+	// The crimson dashed link is described by the explicit entry, and the unknown icon is skipped
+	expected := []string{"Private subnet", "Replication", "Solid line", "Dashed line (blue)", "VPC"}
+	if len(descriptions) != len(expected) {
+		t.Fatalf("Expected entries %v, got %v", expected, descriptions)
+	}
+	for i := range expected {
+		if descriptions[i] != expected[i] {
+			t.Errorf("Entry %d: expected %q, got %q", i, expected[i], descriptions[i])
+		}
+	}
+	if legend.Entries[0].Resource == nil || legend.Entries[1].Link == nil || legend.Entries[4].Resource != vpc {
+		t.Error("Expected the sample types to be inferred from the fields of the entries")
+	}
+
+	template.Legend = &Legend{Position: "NNE"}
+	if _, err := loadLegend(template, ds, resources); err == nil {
+		t.Error("Expected an error for an unsupported position")
+	}
+	template.Legend = &Legend{Entries: []LegendEntry{{Type: "arrow"}}}
+	if _, err := loadLegend(template, ds, resources); err == nil {
+		t.Error("Expected an error for an unknown entry type")
+	}
+	template.Legend = nil
+	if legend, err := loadLegend(template, ds, resources); legend != nil || err != nil {
+		t.Error("Expected no legend without the Legend section")
+	}
+
+	shadow := true
+	if sample := mergeStyle(Style{BorderColor: "blue"}, Style{Shadow: &shadow}); sample.Shadow == nil || !*sample.Shadow {
+		t.Error("Expected the shadow of the style to be merged into the sample")
+	}
+}
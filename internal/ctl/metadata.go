@@ -0,0 +1,153 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package ctl
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"hash/crc32"
+	"image"
+	"image/png"
+	"io"
+
+	"github.com/awslabs/diagram-as-code/internal/types"
+)
+
+// Metadata describes the diagram. It is drawn as a title block and embedded in the output file.
+type Metadata struct {
+	Title          string `yaml:"Title"`
+	Version        string `yaml:"Version"`
+	Author         string `yaml:"Author"`
+	LastUpdated    string `yaml:"LastUpdated"`
+	Classification string `yaml:"Classification"`
+	Position       string `yaml:"Position"` // N, NE, NW, S, SE (default), SW, E, W, or none to only embed the metadata
+}
+
+// pngText is a text chunk of a PNG file
+type pngText struct {
+	Keyword string
+	Text    string
+}
+
+// fields returns the metadata other than the title as keyword and text pairs, skipping empty values
+func (m *Metadata) fields() []pngText {
+	fields := []pngText{}
+	for _, f := range []pngText{
+		{"Version", m.Version},
+		{"Author", m.Author},
+		{"Last Updated", m.LastUpdated},
+		{"Classification", m.Classification},
+	} {
+		if f.Text != "" {
+			fields = append(fields, f)
+		}
+	}
+	return fields
+}
+
+// loadTitleBlock returns the title block of the Metadata section, or nil when there is none to draw
+func loadTitleBlock(metadata *Metadata) (*types.TitleBlock, error) {
+	if metadata == nil || metadata.Position == "none" {
+		return nil, nil
+	}
+	position := metadata.Position
+	if position == "" {
+		position = "SE"
+	}
+	switch position {
+	case "N", "NE", "NW", "S", "SE", "SW", "E", "W":
+	default:
+		return nil, fmt.Errorf("unknown metadata position %s (allowed: N, NE, NW, S, SE, SW, E, W, none)", position)
+	}
+	windrose, err := types.ConvertWindrose(position)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert metadata position: %w", err)
+	}
+	titleBlock := &types.TitleBlock{Title: metadata.Title, Position: windrose}
+	for _, f := range metadata.fields() {
+		titleBlock.Fields = append(titleBlock.Fields, types.TitleBlockField{Name: f.Keyword, Value: f.Text})
+	}
+	return titleBlock, nil
+}
+
+// pngTexts returns the text chunks embedding the metadata
+func pngTexts(metadata *Metadata) []pngText {
+	if metadata == nil {
+		return nil
+	}
+	texts := []pngText{}
+	if metadata.Title != "" {
+		texts = append(texts, pngText{"Title", metadata.Title})
+	}
+	return append(texts, metadata.fields()...)
+}
+
+// encodePNG writes img as PNG with the text chunks after the header.
+// Texts representable in Latin-1 are written as tEXt chunks, and other texts as iTXt chunks in UTF-8.
+func encodePNG(w io.Writer, img image.Image, texts []pngText) error {
+	if len(texts) == 0 {
+		return png.Encode(w, img)
+	}
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return err
+	}
+	// The signature (8 bytes) and the IHDR chunk (25 bytes) come first
+	const headerLength = 8 + 25
+	encoded := buf.Bytes()
+	if _, err := w.Write(encoded[:headerLength]); err != nil {
+		return err
+	}
+	for _, t := range texts {
+		if err := writePNGText(w, t); err != nil {
+			return err
+		}
+	}
+	_, err := w.Write(encoded[headerLength:])
+	return err
+}
+
+// writePNGText writes a tEXt or iTXt chunk
+func writePNGText(w io.Writer, t pngText) error {
+	if len(t.Keyword) == 0 || len(t.Keyword) > 79 {
+		return fmt.Errorf("invalid PNG text keyword %q", t.Keyword)
+	}
+	chunkType := "tEXt"
+	data := append([]byte(t.Keyword), 0)
+	if latin1, ok := toLatin1(t.Text); ok {
+		data = append(data, latin1...)
+	} else {
+		// Uncompressed, without language tag and translated keyword
+		chunkType = "iTXt"
+		data = append(data, 0, 0, 0, 0)
+		data = append(data, t.Text...)
+	}
+	var header [8]byte
+	binary.BigEndian.PutUint32(header[:4], uint32(len(data)))
+	copy(header[4:], chunkType)
+	crc := crc32.NewIEEE()
+	crc.Write(header[4:])
+	crc.Write(data)
+	var footer [4]byte
+	binary.BigEndian.PutUint32(footer[:], crc.Sum32())
+	for _, b := range [][]byte{header[:], data, footer[:]} {
+		if _, err := w.Write(b); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// toLatin1 returns s encoded in Latin-1, or false when s has characters outside Latin-1
+func toLatin1(s string) ([]byte, bool) {
+	b := make([]byte, 0, len(s))
+	for _, r := range s {
+		if r > 0xff {
+			return nil, false
+		}
+		b = append(b, byte(r))
+	}
+	return b, true
+}
@@ -0,0 +1,80 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package ctl
+
+import (
+	"bytes"
+	"encoding/binary"
+	"hash/crc32"
+	"image"
+	"image/png"
+	"testing"
+
+	"github.com/awslabs/diagram-as-code/internal/types"
+)
+
+func TestEncodePNGTexts(t *testing.T) {
+	metadata := &Metadata{
+		Title:          "Order processing",
+		Author:         "Platform team",
+		Classification: "Internal — Confidentiel",
+	}
+	img := image.NewRGBA(image.Rect(0, 0, 4, 4))
+	var buf bytes.Buffer
+	if err := encodePNG(&buf, img, pngTexts(metadata)); err != nil {
+		t.Fatalf("encodePNG failed: %v", err)
+	}
+	if _, err := png.Decode(bytes.NewReader(buf.Bytes())); err != nil {
+		t.Fatalf("Expected a valid PNG, got %v", err)
+	}
+
+	// Read the chunks back
+	data := buf.Bytes()[8:]
+	chunks := map[string]string{}
+	for len(data) >= 12 {
+		length := binary.BigEndian.Uint32(data[:4])
+		chunkType := string(data[4:8])
+		body := data[8 : 8+length]
+		if crc32.ChecksumIEEE(data[4:8+length]) != binary.BigEndian.Uint32(data[8+length:12+length]) {
+			t.Errorf("Invalid CRC of %s chunk", chunkType)
+		}
+		keyword, text, _ := bytes.Cut(body, []byte{0})
+		switch chunkType {
+		case "tEXt":
+			chunks[string(keyword)] = string(text)
+		case "iTXt":
+			chunks[string(keyword)] = string(text[4:])
+		}
+		data = data[12+length:]
+	}
+	expected := map[string]string{
+		"Title":          "Order processing",
+		"Author":         "Platform team",
+		"Classification": "Internal — Confidentiel",
+	}
+	for keyword, text := range expected {
+		if chunks[keyword] != text {
+			t.Errorf("Expected %s %q, got %q", keyword, text, chunks[keyword])
+		}
+	}
+	if _, ok := chunks["Version"]; ok {
+		t.Error("Expected empty fields to be skipped")
+	}
+}
+
+func TestLoadTitleBlock(t *testing.T) {
+	titleBlock, err := loadTitleBlock(&Metadata{Title: "Diagram", Version: "2"})
+	if err != nil {
+		t.Fatalf("loadTitleBlock failed: %v", err)
+	}
+	if titleBlock.Position != types.WINDROSE_SE || len(titleBlock.Fields) != 1 || titleBlock.Fields[0].Name != "Version" {
+		t.Errorf("Unexpected title block %+v", titleBlock)
+	}
+	if titleBlock, err := loadTitleBlock(&Metadata{Title: "Diagram", Position: "none"}); titleBlock != nil || err != nil {
+		t.Error("Expected no title block with position none")
+	}
+	if _, err := loadTitleBlock(&Metadata{Position: "middle"}); err == nil {
+		t.Error("Expected an error for an unsupported position")
+	}
+}
@@ -0,0 +1,64 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package ctl
+
+import (
+	"image/color"
+	"sort"
+
+	"github.com/awslabs/diagram-as-code/internal/types"
+	log "github.com/sirupsen/logrus"
+)
+
+// Color of the leaders from notes to the resources they point to
+var noteLeaderColor = color.RGBA{128, 128, 128, 255}
+
+// loadNotePoints adds a thin dashed leader from each note to the resources in its Points.
+// Leaders are attached to the nearest points of the borders and have no arrow heads.
+func loadNotePoints(template *TemplateStruct, resources map[string]*types.Resource) {
+	names := []string{}
+	for name, v := range template.Resources {
+		if len(v.Points) > 0 {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	leaderColor := noteLeaderColor
+	if darkMode {
+		leaderColor = types.DarkStrokeColor(leaderColor)
+	}
+	for _, name := range names {
+		note, ok := resources[name]
+		if !ok {
+			continue
+		}
+		if !note.IsNote() {
+			log.Warnf("Points of resource %s are ignored: only AWS::Diagram::Note supports Points", name)
+			continue
+		}
+		if note.CollapsedAncestor() != nil {
+			log.Infof("Skip leaders of note %s inside a collapsed group", name)
+			continue
+		}
+		for _, p := range template.Resources[name].Points {
+			target, ok := resources[p]
+			if !ok {
+				log.Warnf("Not found resource %s pointed by note %s", p, name)
+				continue
+			}
+			if collapsed := target.CollapsedAncestor(); collapsed != nil {
+				target = collapsed
+			}
+			if target == note {
+				continue
+			}
+			log.Infof("Add leader(%s-%s)", name, p)
+			leader := new(types.Link).Init(note, types.WINDROSE_NEAREST, types.ArrowHead{}, target, types.WINDROSE_NEAREST, types.ArrowHead{}, 1, leaderColor)
+			leader.SetLineStyle(types.LINE_STYLE_DASHED)
+			note.AddLink(leader)
+			target.AddLink(leader)
+		}
+	}
+}
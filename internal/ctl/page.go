@@ -0,0 +1,134 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package ctl
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// Page is a diagram rendered from the shared Resources section with its own Canvas
+type Page struct {
+	Name      string              `yaml:"Name"`
+	Resources map[string]Resource `yaml:"Resources"`
+	Links     []Link              `yaml:"Links"`
+}
+
+// pageTemplate builds the template for a single page.
+// Page resources override shared resources with the same name, and only the
+// resources reachable from the page Canvas are kept. Shared links are kept
+// when both their source and target (or the link they end on) appear on the page.
+func pageTemplate(template *TemplateStruct, page Page) (*TemplateStruct, error) {
+	if v, ok := page.Resources["Canvas"]; !ok || v.Type != "AWS::Diagram::Canvas" {
+		return nil, fmt.Errorf("page %s must define its own Canvas resource", page.Name)
+	}
+
+	t := &TemplateStruct{
+		Diagram: Diagram{
+			DefinitionFiles: template.DefinitionFiles,
+			Resources:       make(map[string]Resource),
+			Views:           template.Views,
+			LinkCrossing:    template.LinkCrossing,
+			Steps:           template.Steps,
+			Theme:           template.Theme,
+			Styles:          template.Styles,
+			Legend:          template.Legend,
+			Metadata:        template.Metadata,
+		},
+	}
+	for name, v := range template.Resources {
+		t.Resources[name] = v
+	}
+	for name, v := range page.Resources {
+		t.Resources[name] = v
+	}
+
+	reachable := make(map[string]bool)
+	var visit func(name string)
+	visit = func(name string) {
+		v, ok := t.Resources[name]
+		if !ok || reachable[name] {
+			return
+		}
+		reachable[name] = true
+		for _, child := range v.Children {
+			visit(child)
+		}
+		for _, bc := range v.BorderChildren {
+			visit(bc.Resource)
+		}
+	}
+	visit("Canvas")
+
+	// Overlay resources are not part of the tree; keep them while they span a resource on the page
+	names := []string{}
+	for name := range t.Resources {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		v := t.Resources[name]
+		if reachable[name] || len(v.SpanResources) == 0 {
+			continue
+		}
+		spans := []string{}
+		for _, span := range v.SpanResources {
+			if reachable[span] {
+				spans = append(spans, span)
+			}
+		}
+		if len(spans) == 0 {
+			continue
+		}
+		v.SpanResources = spans
+		t.Resources[name] = v
+		reachable[name] = true
+	}
+
+	for _, name := range names {
+		if !reachable[name] {
+			delete(t.Resources, name)
+		}
+	}
+
+	t.Links = filterLinks(template.Links, func(name string) bool {
+		return reachable[name]
+	}, func(link Link) bool {
+		return true
+	})
+	t.Links = append(t.Links, page.Links...)
+	prunePoints(t.Resources)
+
+	log.Infof("Page %s has %d resource(s) and %d link(s)", page.Name, len(t.Resources), len(t.Links))
+	return t, nil
+}
+
+// pageOutputFile returns the output file name for a page, suffixed with the page name.
+// e.g. output.png -> output-overview.png
+func pageOutputFile(outputfile string, name string) string {
+	ext := filepath.Ext(outputfile)
+	return fmt.Sprintf("%s-%s%s", strings.TrimSuffix(outputfile, ext), name, ext)
+}
+
+// validatePages checks that every page has a unique name usable in a file name
+func validatePages(pages []Page) error {
+	names := make(map[string]bool)
+	for i, page := range pages {
+		if page.Name == "" {
+			return fmt.Errorf("page #%d has no Name", i+1)
+		}
+		if strings.ContainsAny(page.Name, `/\`) {
+			return fmt.Errorf("page name %s must not contain path separators", page.Name)
+		}
+		if names[page.Name] {
+			return fmt.Errorf("duplicate page name %s", page.Name)
+		}
+		names[page.Name] = true
+	}
+	return nil
+}
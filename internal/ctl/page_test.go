@@ -0,0 +1,111 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package ctl
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+)
+
+func TestPageTemplate(t *testing.T) {
+	template := &TemplateStruct{
+		Diagram: Diagram{
+			Resources: map[string]Resource{
+				"VPC":    {Type: "AWS::EC2::VPC", Children: []string{"ALB", "DB"}},
+				"ALB":    {Type: "AWS::ElasticLoadBalancingV2::LoadBalancer"},
+				"DB":     {Type: "AWS::RDS::DBInstance"},
+				"User":   {Type: "AWS::Diagram::Resource"},
+				"Bucket": {Type: "AWS::S3::Bucket"},
+				"ASG":    {Type: "AWS::AutoScaling::AutoScalingGroup", SpanResources: []string{"ALB", "Bucket"}},
+			},
+			Links: []Link{
+				{Source: "User", Target: "ALB"},
+				{Source: "ALB", Target: "Bucket"},
+			},
+		},
+	}
+	page := Page{
+		Name: "overview",
+		Resources: map[string]Resource{
+			"Canvas": {Type: "AWS::Diagram::Canvas", Children: []string{"User", "VPC"}},
+			"DB":     {Type: "AWS::RDS::DBInstance", Title: "Primary"},
+		},
+		Links: []Link{
+			{Source: "ALB", Target: "DB"},
+		},
+	}
+
+	pt, err := pageTemplate(template, page)
+	if err != nil {
+		t.Fatalf("pageTemplate() error = %v", err)
+	}
+
+	names := []string{}
+	for name := range pt.Resources {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	if want := []string{"ALB", "ASG", "Canvas", "DB", "User", "VPC"}; !reflect.DeepEqual(names, want) {
+		t.Errorf("Expected resources %v, got %v", want, names)
+	}
+	if want := []string{"ALB"}; !reflect.DeepEqual(pt.Resources["ASG"].SpanResources, want) {
+		t.Errorf("Expected overlay spans %v, got %v", want, pt.Resources["ASG"].SpanResources)
+	}
+	if pt.Resources["DB"].Title != "Primary" {
+		t.Errorf("Expected page resource to override shared resource, got title %q", pt.Resources["DB"].Title)
+	}
+	if want := []Link{{Source: "User", Target: "ALB"}, {Source: "ALB", Target: "DB"}}; !reflect.DeepEqual(pt.Links, want) {
+		t.Errorf("Expected links %v, got %v", want, pt.Links)
+	}
+
+	// The shared pool must not be modified
+	if len(template.Resources) != 6 {
+		t.Errorf("Expected shared resources to be kept, got %d", len(template.Resources))
+	}
+	if want := []string{"ALB", "Bucket"}; !reflect.DeepEqual(template.Resources["ASG"].SpanResources, want) {
+		t.Errorf("Expected shared overlay spans %v, got %v", want, template.Resources["ASG"].SpanResources)
+	}
+
+	if _, err := pageTemplate(template, Page{Name: "nocanvas"}); err == nil {
+		t.Error("Expected error for page without Canvas")
+	}
+}
+
+func TestPageOutputFile(t *testing.T) {
+	tests := []struct {
+		outputfile string
+		name       string
+		want       string
+	}{
+		{"output.png", "overview", "output-overview.png"},
+		{"out/diagram.png", "data", "out/diagram-data.png"},
+		{"diagram", "data", "diagram-data"},
+	}
+	for _, tt := range tests {
+		if got := pageOutputFile(tt.outputfile, tt.name); got != tt.want {
+			t.Errorf("pageOutputFile(%q, %q) = %q, want %q", tt.outputfile, tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestValidatePages(t *testing.T) {
+	tests := []struct {
+		name    string
+		pages   []Page
+		wantErr bool
+	}{
+		{"Unique names", []Page{{Name: "a"}, {Name: "b"}}, false},
+		{"Missing name", []Page{{Name: ""}}, true},
+		{"Duplicate name", []Page{{Name: "a"}, {Name: "a"}}, true},
+		{"Path separator", []Page{{Name: "a/b"}}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := validatePages(tt.pages); (err != nil) != tt.wantErr {
+				t.Errorf("validatePages() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
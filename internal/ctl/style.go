@@ -0,0 +1,119 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package ctl
+
+import (
+	"github.com/awslabs/diagram-as-code/internal/types"
+	log "github.com/sirupsen/logrus"
+)
+
+// Style is a named set of resource and link fields, referenced with Style: <name>.
+// Resource fields are ignored by links, and link fields by resources.
+type Style struct {
+	FillColor       string           `yaml:"FillColor"`
+	BorderColor     string           `yaml:"BorderColor"`
+	BorderType      string           `yaml:"BorderType"`
+	BorderWidth     int              `yaml:"BorderWidth"`
+	CornerRadius    int              `yaml:"CornerRadius"`
+	Shadow          *bool            `yaml:"Shadow"`
+	TitleColor      string           `yaml:"TitleColor"`
+	TitleFillColor  string           `yaml:"TitleFillColor"`
+	Font            string           `yaml:"Font"`
+	FontSize        float64          `yaml:"FontSize"`
+	FontWeight      string           `yaml:"FontWeight"`
+	Options         *ResourceOptions `yaml:"Options"`
+	LineColor       string           `yaml:"LineColor"`
+	LineWidth       int              `yaml:"LineWidth"`
+	LineStyle       string           `yaml:"LineStyle"`
+	DashPattern     []float64        `yaml:"DashPattern"`
+	SourceArrowHead types.ArrowHead  `yaml:"SourceArrowHead"`
+	TargetArrowHead types.ArrowHead  `yaml:"TargetArrowHead"`
+}
+
+// mergeString returns the inline value, or the style value when the inline value is not set
+func mergeString(inline, style string) string {
+	if inline != "" {
+		return inline
+	}
+	return style
+}
+
+// applyStyles copies the fields of the styles referenced by resources and links into the fields
+// they do not set. As presets are applied before these fields, values are merged in the order
+// definition -> preset -> style -> inline. It must be called before loadResources.
+func applyStyles(template *TemplateStruct) {
+	for name, v := range template.Resources {
+		if v.Style == "" {
+			continue
+		}
+		style, ok := template.Styles[v.Style]
+		if !ok {
+			log.Warnf("Unknown style %s on resource %s", v.Style, name)
+			continue
+		}
+		v.FillColor = mergeString(v.FillColor, style.FillColor)
+		v.BorderColor = mergeString(v.BorderColor, style.BorderColor)
+		v.BorderType = mergeString(v.BorderType, style.BorderType)
+		v.TitleColor = mergeString(v.TitleColor, style.TitleColor)
+		v.TitleFillColor = mergeString(v.TitleFillColor, style.TitleFillColor)
+		v.Font = mergeString(v.Font, style.Font)
+		v.FontWeight = mergeString(v.FontWeight, style.FontWeight)
+		if v.FontSize == 0 {
+			v.FontSize = style.FontSize
+		}
+		if v.BorderWidth == 0 {
+			v.BorderWidth = style.BorderWidth
+		}
+		if v.CornerRadius == 0 {
+			v.CornerRadius = style.CornerRadius
+		}
+		if v.Shadow == nil {
+			v.Shadow = style.Shadow
+		}
+		if style.Options != nil {
+			options := *style.Options
+			if v.Options != nil {
+				for _, o := range []struct{ dst, src **bool }{
+					{&options.GroupingOffset, &v.Options.GroupingOffset},
+					{&options.GroupingOffsetDirection, &v.Options.GroupingOffsetDirection},
+					{&options.UnorderedChildren, &v.Options.UnorderedChildren},
+					{&options.Collapsed, &v.Options.Collapsed},
+					{&options.Bundling, &v.Options.Bundling},
+				} {
+					if *o.src != nil {
+						*o.dst = *o.src
+					}
+				}
+			}
+			v.Options = &options
+		}
+		template.Resources[name] = v
+	}
+
+	for i, v := range template.Links {
+		if v.Style == "" {
+			continue
+		}
+		style, ok := template.Styles[v.Style]
+		if !ok {
+			log.Warnf("Unknown style %s on link(%s-%s)", v.Style, v.Source, v.Target)
+			continue
+		}
+		v.LineColor = mergeString(v.LineColor, style.LineColor)
+		v.LineStyle = mergeString(v.LineStyle, style.LineStyle)
+		if v.LineWidth == 0 {
+			v.LineWidth = style.LineWidth
+		}
+		if len(v.DashPattern) == 0 {
+			v.DashPattern = style.DashPattern
+		}
+		if v.SourceArrowHead == (types.ArrowHead{}) {
+			v.SourceArrowHead = style.SourceArrowHead
+		}
+		if v.TargetArrowHead == (types.ArrowHead{}) {
+			v.TargetArrowHead = style.TargetArrowHead
+		}
+		template.Links[i] = v
+	}
+}
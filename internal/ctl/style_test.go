@@ -0,0 +1,81 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package ctl
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/awslabs/diagram-as-code/internal/types"
+)
+
+func TestApplyStyles(t *testing.T) {
+	enabled, disabled := true, false
+	template := &TemplateStruct{
+		Diagram: Diagram{
+			Styles: map[string]Style{
+				"important": {
+					FillColor:    "#FFEEEE",
+					BorderColor:  "red",
+					TitleColor:   "red",
+					FontSize:     18,
+					FontWeight:   "bold",
+					CornerRadius: 12,
+					Shadow:       &enabled,
+					Options:      &ResourceOptions{GroupingOffset: &enabled, Bundling: &enabled},
+				},
+				"critical-path": {
+					LineColor:       "crimson",
+					LineWidth:       4,
+					LineStyle:       "dashed",
+					TargetArrowHead: types.ArrowHead{Type: "Open"},
+				},
+			},
+			Resources: map[string]Resource{
+				"A": {Type: "AWS::Diagram::Resource", Style: "important", BorderColor: "blue", Shadow: &disabled, Options: &ResourceOptions{Bundling: &disabled}},
+				"B": {Type: "AWS::Diagram::Resource", Style: "unknown", FillColor: "white"},
+				"C": {Type: "AWS::Diagram::Resource"},
+			},
+			Links: []Link{
+				{Source: "A", Target: "B", Style: "critical-path", LineStyle: "solid"},
+				{Source: "B", Target: "C", Style: "critical-path", TargetArrowHead: types.ArrowHead{Type: "Diamond"}},
+			},
+		},
+	}
+	applyStyles(template)
+
+	a := template.Resources["A"]
+	if a.FillColor != "#FFEEEE" || a.TitleColor != "red" {
+		t.Errorf("Expected style colors on A, got fill %q title %q", a.FillColor, a.TitleColor)
+	}
+	if a.FontSize != 18 || a.FontWeight != "bold" {
+		t.Errorf("Expected style font size and weight on A, got %v %q", a.FontSize, a.FontWeight)
+	}
+	if a.CornerRadius != 12 || a.Shadow == nil || *a.Shadow {
+		t.Errorf("Expected the style corner radius and the inline shadow on A, got %d %v", a.CornerRadius, a.Shadow)
+	}
+	if a.BorderColor != "blue" {
+		t.Errorf("Expected the inline border color to win over the style, got %q", a.BorderColor)
+	}
+	if a.Options == nil || a.Options.GroupingOffset == nil || !*a.Options.GroupingOffset || a.Options.Bundling == nil || *a.Options.Bundling {
+		t.Errorf("Expected options merged field by field with inline values first, got %+v", a.Options)
+	}
+	if b := template.Resources["B"]; b.FillColor != "white" || b.BorderColor != "" {
+		t.Errorf("Expected B to be unchanged by an unknown style, got %+v", b)
+	}
+	if c := template.Resources["C"]; !reflect.DeepEqual(c, Resource{Type: "AWS::Diagram::Resource"}) {
+		t.Errorf("Expected C without style to be unchanged, got %+v", c)
+	}
+
+	l1, l2 := template.Links[0], template.Links[1]
+	if l1.LineColor != "crimson" || l1.LineWidth != 4 || l1.LineStyle != "solid" || l1.TargetArrowHead.Type != "Open" {
+		t.Errorf("Unexpected first link after applying style: %+v", l1)
+	}
+	if l2.LineStyle != "dashed" || l2.TargetArrowHead.Type != "Diamond" {
+		t.Errorf("Unexpected second link after applying style: %+v", l2)
+	}
+	if template.Styles["important"].Options.Bundling != &enabled {
+		t.Error("Expected the options of the style not to be modified")
+	}
+}
@@ -0,0 +1,193 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package ctl
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"image/color"
+	"os"
+	"strings"
+
+	"github.com/awslabs/diagram-as-code/internal/definition"
+	"github.com/awslabs/diagram-as-code/internal/types"
+	log "github.com/sirupsen/logrus"
+	"golang.org/x/exp/maps"
+	"gopkg.in/yaml.v3"
+)
+
+// Theme defines a palette of named colors and the default colors and font of a diagram.
+// It is applied after the definition of each resource type, before presets and the
+// values set on each resource or link.
+type Theme struct {
+	Dark            bool              `yaml:"Dark"`            // Render for a dark background
+	Palette         map[string]string `yaml:"Palette"`         // Colors referenced as $name
+	BackgroundColor string            `yaml:"BackgroundColor"` // Fill of the Canvas
+	FillColor       string            `yaml:"FillColor"`       // Fill of groups
+	BorderColor     string            `yaml:"BorderColor"`     // Border of groups
+	TitleColor      string            `yaml:"TitleColor"`
+	Font            string            `yaml:"Font"`
+	FontFallbacks   []string          `yaml:"FontFallbacks"` // Fonts drawing characters missing from the title fonts
+	LineColor       string            `yaml:"LineColor"`     // Color of links
+	LabelColor      string            `yaml:"LabelColor"`    // Color of link labels
+}
+
+// palette holds the colors of the theme of the diagram being created, referenced as $name
+var palette = map[string]color.RGBA{}
+
+// darkMode remaps the colors of definitions and uses dark icons of the diagram being created
+var darkMode = false
+
+// parseHexColor parses #RRGGBB and #RRGGBBAA colors
+func parseHexColor(c string) (color.RGBA, error) {
+	b, err := hex.DecodeString(strings.TrimPrefix(c, "#"))
+	if err != nil || (len(b) != 3 && len(b) != 4) {
+		return color.RGBA{}, fmt.Errorf("failed to parse color string '%s': expected #RRGGBB or #RRGGBBAA", c)
+	}
+	if len(b) == 3 {
+		b = append(b, 255)
+	}
+	return color.RGBA{b[0], b[1], b[2], b[3]}, nil
+}
+
+// loadTheme merges the theme file given with --theme and the Theme section, which takes
+// precedence, into template.Theme and loads its palette
+func loadTheme(template *TemplateStruct, opts *CreateOptions) error {
+	palette = map[string]color.RGBA{}
+
+	theme := Theme{}
+	if opts != nil && opts.ThemeFile != "" {
+		log.Infof("Load theme file %s", opts.ThemeFile)
+		data, err := getTemplate(opts.ThemeFile)
+		if err != nil {
+			return fmt.Errorf("failed to read theme file: %w", err)
+		}
+		dec := yaml.NewDecoder(bytes.NewReader(data))
+		dec.KnownFields(true)
+		if err := dec.Decode(&theme); err != nil {
+			return fmt.Errorf("failed to decode theme file: %w", err)
+		}
+	}
+	if t := template.Theme; t != nil {
+		theme.Dark = theme.Dark || t.Dark
+		if theme.Palette == nil {
+			theme.Palette = map[string]string{}
+		}
+		maps.Copy(theme.Palette, t.Palette)
+		if len(t.FontFallbacks) > 0 {
+			theme.FontFallbacks = t.FontFallbacks
+		}
+		for _, v := range []struct{ dst, src *string }{
+			{&theme.BackgroundColor, &t.BackgroundColor},
+			{&theme.FillColor, &t.FillColor},
+			{&theme.BorderColor, &t.BorderColor},
+			{&theme.TitleColor, &t.TitleColor},
+			{&theme.Font, &t.Font},
+			{&theme.LineColor, &t.LineColor},
+			{&theme.LabelColor, &t.LabelColor},
+		} {
+			if *v.src != "" {
+				*v.dst = *v.src
+			}
+		}
+	}
+	if opts != nil && opts.Dark {
+		theme.Dark = true
+	}
+	template.Theme = &theme
+	darkMode = theme.Dark
+	types.SetDarkMode(darkMode)
+
+	// Fallback fonts given on the command line are tried first
+	fallbacks := []string{}
+	if opts != nil {
+		fallbacks = append(fallbacks, opts.FontFallbacks...)
+		types.SetFontDirs(opts.FontDirs)
+	} else {
+		types.SetFontDirs(nil)
+	}
+	types.SetFontFallbacks(append(fallbacks, theme.FontFallbacks...))
+
+	for name, value := range theme.Palette {
+		if strings.HasPrefix(strings.TrimSpace(value), "$") {
+			return fmt.Errorf("palette color %s must not reference another palette color", name)
+		}
+		c, err := stringToColor(value)
+		if err != nil {
+			return fmt.Errorf("failed to parse palette color %s: %w", name, err)
+		}
+		palette[name] = c
+	}
+	return nil
+}
+
+// definitionColor parses a color of a definition file. In dark mode, fills are inverted
+// and dark borders and text are lightened.
+func definitionColor(c string, fill bool) (color.RGBA, error) {
+	rgba, err := stringToColor(c)
+	if err != nil || !darkMode {
+		return rgba, err
+	}
+	if fill {
+		return types.DarkFillColor(rgba), nil
+	}
+	return types.DarkStrokeColor(rgba), nil
+}
+
+// definitionIconPath returns the icon of a definition, or its dark icon in dark mode
+func definitionIconPath(def *definition.Definition) string {
+	if darkMode && def.DarkCacheFilePath != "" {
+		if _, err := os.Stat(def.DarkCacheFilePath); err == nil {
+			return def.DarkCacheFilePath
+		}
+		log.Warnf("Dark icon %s is not found. Use %s instead.", def.DarkCacheFilePath, def.CacheFilePath)
+	}
+	return def.CacheFilePath
+}
+
+// applyTheme applies the theme to a resource loaded from its definition.
+// Groups other than stacks get the fill and border colors, and the Canvas the background color.
+func applyTheme(theme *Theme, resource *types.Resource, name string, v Resource) error {
+	if theme == nil {
+		return nil
+	}
+	isStack := v.Type == "AWS::Diagram::VerticalStack" || v.Type == "AWS::Diagram::HorizontalStack"
+	if v.Type == "AWS::Diagram::Canvas" {
+		if theme.BackgroundColor != "" {
+			c, err := stringToColor(theme.BackgroundColor)
+			if err != nil {
+				return fmt.Errorf("failed to parse theme background color: %w", err)
+			}
+			resource.SetFillColor(c)
+		}
+	} else if len(v.Children) > 0 && !isStack {
+		if theme.FillColor != "" {
+			c, err := stringToColor(theme.FillColor)
+			if err != nil {
+				return fmt.Errorf("failed to parse theme fill color for resource %s: %w", name, err)
+			}
+			resource.SetFillColor(c)
+		}
+		if theme.BorderColor != "" {
+			c, err := stringToColor(theme.BorderColor)
+			if err != nil {
+				return fmt.Errorf("failed to parse theme border color for resource %s: %w", name, err)
+			}
+			resource.SetBorderColor(c)
+		}
+	}
+	// Notes keep their dark text on the note color
+	if theme.TitleColor != "" && v.Type != "AWS::Diagram::Note" {
+		c, err := stringToColor(theme.TitleColor)
+		if err != nil {
+			return fmt.Errorf("failed to parse theme title color for resource %s: %w", name, err)
+		}
+		resource.SetLabel(nil, &c, nil)
+	}
+	if theme.Font != "" {
+		resource.SetLabel(nil, nil, &theme.Font)
+	}
+	return nil
+}
@@ -0,0 +1,154 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package ctl
+
+import (
+	"image/color"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/awslabs/diagram-as-code/internal/types"
+)
+
+func TestStringToColor(t *testing.T) {
+	palette = map[string]color.RGBA{"primary": {35, 47, 62, 255}}
+	defer func() { palette = map[string]color.RGBA{} }()
+
+	tests := []struct {
+		input    string
+		expected color.RGBA
+		wantErr  bool
+	}{
+		{"rgba(1,2,3,4)", color.RGBA{1, 2, 3, 4}, false},
+		{"rgba(1, 2, 3, 4)", color.RGBA{1, 2, 3, 4}, false},
+		{"#FF9900", color.RGBA{255, 153, 0, 255}, false},
+		{"#ff990080", color.RGBA{255, 153, 0, 128}, false},
+		{"orange", color.RGBA{255, 165, 0, 255}, false},
+		{"DarkBlue", color.RGBA{0, 0, 139, 255}, false},
+		{"transparent", color.RGBA{0, 0, 0, 0}, false},
+		{"$primary", color.RGBA{35, 47, 62, 255}, false},
+		{"$unknown", color.RGBA{}, true},
+		{"#F90", color.RGBA{}, true},
+		{"#GGGGGG", color.RGBA{}, true},
+		{"notacolor", color.RGBA{}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			c, err := stringToColor(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("stringToColor(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			}
+			if c != tt.expected {
+				t.Errorf("stringToColor(%q) = %v, expected %v", tt.input, c, tt.expected)
+			}
+		})
+	}
+}
+
+func TestLoadTheme(t *testing.T) {
+	defer func() { palette = map[string]color.RGBA{} }()
+
+	themeFile := filepath.Join(t.TempDir(), "theme.yaml")
+	content := "Palette:\n  primary: navy\n  accent: orange\nLineColor: $accent\nTitleColor: $primary\n"
+	if err := os.WriteFile(themeFile, []byte(content), 0600); err != nil {
+		t.Fatalf("failed to write theme file: %v", err)
+	}
+	template := &TemplateStruct{
+		Diagram: Diagram{
+			Theme: &Theme{
+				Palette:     map[string]string{"primary": "#232F3E"},
+				BorderColor: "$primary",
+			},
+			Resources: map[string]Resource{
+				"Canvas": {Type: "AWS::Diagram::Canvas", Children: []string{"A"}},
+				"A":      {Type: "AWS::Diagram::Resource"},
+				"B":      {Type: "AWS::Diagram::Resource"},
+			},
+			Links: []Link{
+				{Source: "A", Target: "B"},
+				{Source: "B", Target: "A", LineColor: "red"},
+			},
+		},
+	}
+	if err := loadTheme(template, &CreateOptions{ThemeFile: themeFile}); err != nil {
+		t.Fatalf("loadTheme failed: %v", err)
+	}
+	if palette["primary"] != (color.RGBA{35, 47, 62, 255}) {
+		t.Errorf("Expected the Theme section to override the theme file palette, got %v", palette["primary"])
+	}
+	if palette["accent"] != (color.RGBA{255, 165, 0, 255}) {
+		t.Errorf("Expected the accent color from the theme file, got %v", palette["accent"])
+	}
+	if template.Theme.LineColor != "$accent" || template.Theme.BorderColor != "$primary" {
+		t.Errorf("Expected the theme file and the Theme section to be merged, got %+v", template.Theme)
+	}
+
+	resources := map[string]*types.Resource{
+		"A": new(types.Resource).Init(),
+		"B": new(types.Resource).Init(),
+	}
+	if err := loadLinks(template, resources); err != nil {
+		t.Fatalf("loadLinks failed: %v", err)
+	}
+	for name, expected := range map[string]color.RGBA{"A": {255, 165, 0, 255}, "B": {255, 0, 0, 255}} {
+		for _, link := range resources[name].GetLinks() {
+			if link.Source == resources[name] && link.GetLineColor() != expected {
+				t.Errorf("Expected line color %v for the link from %s, got %v", expected, name, link.GetLineColor())
+			}
+		}
+	}
+
+	template.Theme = &Theme{Palette: map[string]string{"a": "$b", "b": "red"}}
+	if err := loadTheme(template, nil); err == nil {
+		t.Error("Expected error for a palette color referencing another palette color")
+	}
+}
+
+func TestDarkMode(t *testing.T) {
+	defer func() {
+		darkMode = false
+		types.SetDarkMode(false)
+	}()
+
+	template := &TemplateStruct{
+		Diagram: Diagram{
+			Links: []Link{{Source: "A", Target: "B"}},
+		},
+	}
+	if err := loadTheme(template, &CreateOptions{Dark: true}); err != nil {
+		t.Fatalf("loadTheme failed: %v", err)
+	}
+	if !darkMode || !template.Theme.Dark {
+		t.Fatal("Expected --dark to enable dark mode")
+	}
+
+	fill, err := definitionColor("rgba(240, 235, 255, 255)", true)
+	if err != nil || fill != (color.RGBA{5, 0, 20, 255}) {
+		t.Errorf("Expected a light fill to become dark, got %v (%v)", fill, err)
+	}
+	border, err := definitionColor("rgba(0, 0, 0, 255)", false)
+	if err != nil || border != (color.RGBA{255, 255, 255, 255}) {
+		t.Errorf("Expected a black border to become white, got %v (%v)", border, err)
+	}
+
+	resources := map[string]*types.Resource{
+		"A": new(types.Resource).Init(),
+		"B": new(types.Resource).Init(),
+	}
+	if err := loadLinks(template, resources); err != nil {
+		t.Fatalf("loadLinks failed: %v", err)
+	}
+	if c := resources["A"].GetLinks()[0].GetLineColor(); c != (color.RGBA{255, 255, 255, 255}) {
+		t.Errorf("Expected a light default line color, got %v", c)
+	}
+
+	template.Theme = nil
+	if err := loadTheme(template, nil); err != nil {
+		t.Fatalf("loadTheme failed: %v", err)
+	}
+	if darkMode {
+		t.Error("Expected dark mode to be reset for the next diagram")
+	}
+}
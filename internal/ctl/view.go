@@ -0,0 +1,248 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package ctl
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+	"golang.org/x/exp/slices"
+)
+
+// View is a named set of tag filters, selected with the --view option
+type View struct {
+	IncludeTags []string `yaml:"IncludeTags"`
+	ExcludeTags []string `yaml:"ExcludeTags"`
+}
+
+type tagFilter struct {
+	include []string
+	exclude []string
+}
+
+// excludes reports whether any of the tags is excluded
+func (f tagFilter) excludes(tags []string) bool {
+	for _, tag := range tags {
+		if slices.Contains(f.exclude, tag) {
+			return true
+		}
+	}
+	return false
+}
+
+// matches reports whether the tags pass the filter.
+// Untagged items only pass when no include tags are given unless allowUntagged is set.
+func (f tagFilter) matches(tags []string, allowUntagged bool) bool {
+	if f.excludes(tags) {
+		return false
+	}
+	if len(f.include) == 0 {
+		return true
+	}
+	if len(tags) == 0 {
+		return allowUntagged
+	}
+	for _, tag := range tags {
+		if slices.Contains(f.include, tag) {
+			return true
+		}
+	}
+	return false
+}
+
+// applyTagFilter removes resources and links that do not belong to the selected view.
+// Groups left without children are pruned, and links whose source or target was
+// removed are dropped. It must be called before loadResources.
+func applyTagFilter(template *TemplateStruct, opts *CreateOptions) error {
+	if opts == nil {
+		return nil
+	}
+	filter := tagFilter{
+		include: append([]string{}, opts.IncludeTags...),
+		exclude: append([]string{}, opts.ExcludeTags...),
+	}
+	if opts.View != "" {
+		view, ok := template.Views[opts.View]
+		if !ok {
+			names := []string{}
+			for name := range template.Views {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+			return fmt.Errorf("unknown view %s, defined views are %v", opts.View, names)
+		}
+		filter.include = append(filter.include, view.IncludeTags...)
+		filter.exclude = append(filter.exclude, view.ExcludeTags...)
+	}
+	if len(filter.include) == 0 && len(filter.exclude) == 0 {
+		return nil
+	}
+	log.Infof("Apply tag filter (include: %v, exclude: %v)", filter.include, filter.exclude)
+
+	removed := make(map[string]bool)
+	visited := make(map[string]bool)
+	var removeTree func(name string)
+	removeTree = func(name string) {
+		v, ok := template.Resources[name]
+		if !ok || removed[name] {
+			return
+		}
+		removed[name] = true
+		for _, child := range v.Children {
+			removeTree(child)
+		}
+		for _, bc := range v.BorderChildren {
+			removeTree(bc.Resource)
+		}
+	}
+
+	var visit func(name string) bool
+	visit = func(name string) bool {
+		v, ok := template.Resources[name]
+		if !ok {
+			return false
+		}
+		if visited[name] {
+			return !removed[name]
+		}
+		visited[name] = true
+		isCanvas := v.Type == "AWS::Diagram::Canvas"
+		if !isCanvas && filter.excludes(v.Tags) {
+			removeTree(name)
+			return false
+		}
+
+		children := []string{}
+		for _, child := range v.Children {
+			if visit(child) {
+				children = append(children, child)
+			}
+		}
+		borderChildren := []BorderChild{}
+		for _, bc := range v.BorderChildren {
+			if visit(bc.Resource) {
+				borderChildren = append(borderChildren, bc)
+			}
+		}
+
+		kept := isCanvas
+		if !kept {
+			if len(v.Children) > 0 {
+				// Group: keep while it has children, or when it is tagged for this view
+				kept = len(children) > 0 || filter.matches(v.Tags, false)
+			} else {
+				kept = filter.matches(v.Tags, false)
+			}
+		}
+		if !kept {
+			removeTree(name)
+			return false
+		}
+		v.Children = children
+		v.BorderChildren = borderChildren
+		template.Resources[name] = v
+		return true
+	}
+
+	names := []string{}
+	for name := range template.Resources {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if template.Resources[name].Type == "AWS::Diagram::Canvas" {
+			visit(name)
+		}
+	}
+
+	// Overlay resources are not part of the tree; keep them while they span a visible resource
+	for _, name := range names {
+		v := template.Resources[name]
+		if len(v.SpanResources) == 0 {
+			continue
+		}
+		spans := []string{}
+		for _, span := range v.SpanResources {
+			if !removed[span] {
+				spans = append(spans, span)
+			}
+		}
+		if len(spans) == 0 || (len(v.Tags) > 0 && !filter.matches(v.Tags, false)) {
+			removed[name] = true
+			continue
+		}
+		v.SpanResources = spans
+		template.Resources[name] = v
+	}
+
+	for name := range removed {
+		log.Infof("Filter out resource %s", name)
+		delete(template.Resources, name)
+	}
+
+	links := filterLinks(template.Links, func(name string) bool {
+		_, ok := template.Resources[name]
+		return ok
+	}, func(link Link) bool {
+		return filter.matches(link.Tags, true)
+	})
+	log.Infof("Filter out %d link(s)", len(template.Links)-len(links))
+	template.Links = links
+	prunePoints(template.Resources)
+	return nil
+}
+
+// prunePoints removes the resources that are not in resources from the Points of notes
+func prunePoints(resources map[string]Resource) {
+	for name, v := range resources {
+		if len(v.Points) == 0 {
+			continue
+		}
+		points := []string{}
+		for _, p := range v.Points {
+			if _, ok := resources[p]; ok {
+				points = append(points, p)
+			}
+		}
+		v.Points = points
+		resources[name] = v
+	}
+}
+
+// filterLinks returns the links whose source and target resources are kept and that pass keepLink.
+// Links ending on a link (Target: link:<Id>) are kept when the link they end on is kept.
+func filterLinks(links []Link, keepResource func(name string) bool, keepLink func(link Link) bool) []Link {
+	kept := make([]bool, len(links))
+	keptIds := make(map[string]bool)
+	// Repeat until no more links are kept, as links may end on links defined after them
+	for changed := true; changed; {
+		changed = false
+		for i, link := range links {
+			if kept[i] || !keepResource(link.Source) || !keepLink(link) {
+				continue
+			}
+			if id, ok := strings.CutPrefix(link.Target, "link:"); ok {
+				if !keptIds[id] {
+					continue
+				}
+			} else if !keepResource(link.Target) {
+				continue
+			}
+			kept[i] = true
+			changed = true
+			if link.Id != "" {
+				keptIds[link.Id] = true
+			}
+		}
+	}
+	result := []Link{}
+	for i, link := range links {
+		if kept[i] {
+			result = append(result, link)
+		}
+	}
+	return result
+}
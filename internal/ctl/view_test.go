@@ -0,0 +1,121 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package ctl
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+)
+
+func newViewTestTemplate() *TemplateStruct {
+	return &TemplateStruct{
+		Diagram: Diagram{
+			Resources: map[string]Resource{
+				"Canvas":   {Type: "AWS::Diagram::Canvas", Children: []string{"VPC", "User"}},
+				"User":     {Type: "AWS::Diagram::Resource", Tags: []string{"network", "security"}},
+				"VPC":      {Type: "AWS::EC2::VPC", Children: []string{"ALB", "Data"}},
+				"ALB":      {Type: "AWS::ElasticLoadBalancingV2::LoadBalancer", Tags: []string{"network"}},
+				"Data":     {Type: "AWS::Diagram::VerticalStack", Children: []string{"Bucket", "Database"}},
+				"Bucket":   {Type: "AWS::S3::Bucket", Tags: []string{"data"}},
+				"Database": {Type: "AWS::RDS::DBInstance", Tags: []string{"data"}},
+			},
+			Links: []Link{
+				{Source: "User", Target: "ALB"},
+				{Id: "data", Source: "ALB", Target: "Bucket"},
+				{Source: "User", Target: "ALB", Tags: []string{"security"}},
+				{Source: "Database", Target: "link:data"},
+			},
+			Views: map[string]View{
+				"network": {IncludeTags: []string{"network"}},
+			},
+		},
+	}
+}
+
+func TestApplyTagFilter(t *testing.T) {
+	tests := []struct {
+		name          string
+		opts          *CreateOptions
+		wantResources []string
+		wantLinks     int
+		wantErr       bool
+	}{
+		{
+			name:          "No filter",
+			opts:          &CreateOptions{},
+			wantResources: []string{"ALB", "Bucket", "Canvas", "Data", "Database", "User", "VPC"},
+			wantLinks:     4,
+		},
+		{
+			name:          "View prunes empty groups and dangling links",
+			opts:          &CreateOptions{View: "network"},
+			wantResources: []string{"ALB", "Canvas", "User", "VPC"},
+			wantLinks:     1,
+		},
+		{
+			name:          "Exclude tags",
+			opts:          &CreateOptions{ExcludeTags: []string{"security"}},
+			wantResources: []string{"ALB", "Bucket", "Canvas", "Data", "Database", "VPC"},
+			wantLinks:     2,
+		},
+		{
+			name:          "Include and exclude tags",
+			opts:          &CreateOptions{IncludeTags: []string{"network", "data"}, ExcludeTags: []string{"security"}},
+			wantResources: []string{"ALB", "Bucket", "Canvas", "Data", "Database", "VPC"},
+			wantLinks:     2,
+		},
+		{
+			name:    "Unknown view",
+			opts:    &CreateOptions{View: "unknown"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			template := newViewTestTemplate()
+			err := applyTagFilter(template, tt.opts)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("applyTagFilter() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			names := []string{}
+			for name := range template.Resources {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+			if !reflect.DeepEqual(names, tt.wantResources) {
+				t.Errorf("Expected resources %v, got %v", tt.wantResources, names)
+			}
+			if len(template.Links) != tt.wantLinks {
+				t.Errorf("Expected %d links, got %d", tt.wantLinks, len(template.Links))
+			}
+			for name, v := range template.Resources {
+				for _, child := range v.Children {
+					if _, ok := template.Resources[child]; !ok {
+						t.Errorf("Resource %s still references removed child %s", name, child)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestPrunePoints(t *testing.T) {
+	resources := map[string]Resource{
+		"Note": {Type: "AWS::Diagram::Note", Points: []string{"A", "Removed"}},
+		"A":    {Type: "AWS::Diagram::Resource"},
+	}
+	points := resources["Note"].Points
+	prunePoints(resources)
+	if got := resources["Note"].Points; len(got) != 1 || got[0] != "A" {
+		t.Errorf("Expected Points [A], got %v", got)
+	}
+	if points[1] != "Removed" {
+		t.Error("Expected the original Points not to be modified")
+	}
+}
@@ -6,17 +6,20 @@ package definition
 import "fmt"
 
 type Definition struct {
-	Type          string              `yaml:"Type"`
-	Icon          *DefinitionIcon     `yaml:"Icon"`
-	Label         *DefinitionLabel    `yaml:"Label"`
-	Fill          *DefinitionFill     `yaml:"Fill"`
-	Border        *DefinitionBorder   `yaml:"Border"`
-	HeaderAlign   string              `yaml:"HeaderAlign"`
-	Directory     DefinitionDirectory `yaml:"Directory"`
-	ZipFile       DefinitionZipFile   `yaml:"ZipFile"`
-	CFn           DefinitionCFn       `yaml:"CFn"`
-	Parent        *Definition
-	CacheFilePath string
+	Type              string              `yaml:"Type"`
+	Icon              *DefinitionIcon     `yaml:"Icon"`
+	DarkIcon          *DefinitionIcon     `yaml:"DarkIcon"` // Icon used in dark mode
+	Label             *DefinitionLabel    `yaml:"Label"`
+	Fill              *DefinitionFill     `yaml:"Fill"`
+	Border            *DefinitionBorder   `yaml:"Border"`
+	HeaderAlign       string              `yaml:"HeaderAlign"`
+	Directory         DefinitionDirectory `yaml:"Directory"`
+	ZipFile           DefinitionZipFile   `yaml:"ZipFile"`
+	CFn               DefinitionCFn       `yaml:"CFn"`
+	DarkVariant       string              `yaml:"DarkVariant"` // Icon pack with the same paths used in dark mode
+	Parent            *Definition
+	CacheFilePath     string
+	DarkCacheFilePath string
 }
 
 type DefinitionLabel struct {
@@ -31,8 +34,11 @@ type DefinitionFill struct {
 }
 
 type DefinitionBorder struct {
-	Color string `yaml:"Color"`
-	Type  string `yaml:"Type"`
+	Color        string `yaml:"Color"`
+	Type         string `yaml:"Type"`
+	Width        int    `yaml:"Width"`
+	CornerRadius int    `yaml:"CornerRadius"`
+	Shadow       bool   `yaml:"Shadow"`
 }
 
 // [TODO] make interface
@@ -82,6 +88,9 @@ func (d *Definition) String() string {
 	if d.CacheFilePath != "" {
 		res += d.CacheFilePath
 	}
+	if d.DarkCacheFilePath != "" {
+		res += fmt.Sprintf("  DarkCacheFilePath: %s\n", d.DarkCacheFilePath)
+	}
 	res += "}\n"
 	return res
 }
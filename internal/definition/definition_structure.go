@@ -138,6 +138,24 @@ func (ds *DefinitionStructure) LoadDefinitions(filePath string) error {
 					v.CacheFilePath = fmt.Sprintf("%s/%s", sourceDef.CacheFilePath, v.Icon.Path)
 				}
 			}
+			// The dark icon is given by DarkIcon, or found at the same path in the dark variant of the icon pack
+			darkIcon := v.DarkIcon
+			if darkIcon == nil && v.Icon != nil && v.Icon.Source != "" {
+				if sourceDef, ok := b.Definitions[v.Icon.Source]; ok && sourceDef.DarkVariant != "" {
+					darkIcon = &DefinitionIcon{Source: sourceDef.DarkVariant, Path: v.Icon.Path}
+				}
+			}
+			if darkIcon != nil && darkIcon.Path != "" && darkIcon.Source != "" {
+				sourceDef, ok := b.Definitions[darkIcon.Source]
+				if !ok {
+					return fmt.Errorf("Dark icon source %s not found in definitions", darkIcon.Source)
+				}
+				if sourceDef.CacheFilePath == "" {
+					q = append(q, k)
+					break
+				}
+				v.DarkCacheFilePath = fmt.Sprintf("%s/%s", sourceDef.CacheFilePath, darkIcon.Path)
+			}
 		}
 		q = q[1:]
 	}
@@ -1,6 +1,8 @@
 package definition
 
 import (
+	"os"
+	"path/filepath"
 	"reflect"
 	"testing"
 )
@@ -92,3 +94,53 @@ func TestDefinitionStructure_LoadDefinitions(t *testing.T) {
 		}
 	})
 }
+
+func TestDefinitionStructure_LoadDarkIcons(t *testing.T) {
+	data := `Definitions:
+  Icons:
+    Type: Directory
+    Directory:
+      Path: "light/"
+    DarkVariant: DarkIcons
+  DarkIcons:
+    Type: Directory
+    Directory:
+      Path: "dark/"
+  PackResource:
+    Type: Resource
+    Icon:
+      Source: Icons
+      Path: a.png
+  ExplicitResource:
+    Type: Resource
+    Icon:
+      Source: Icons
+      Path: b.png
+    DarkIcon:
+      Source: DarkIcons
+      Path: b-dark.png
+  LightOnlyResource:
+    Type: Resource
+    Icon:
+      Source: DarkIcons
+      Path: c.png
+`
+	filePath := filepath.Join(t.TempDir(), "definitions.yaml")
+	if err := os.WriteFile(filePath, []byte(data), 0600); err != nil {
+		t.Fatalf("Failed to write definition file: %v", err)
+	}
+	ds := &DefinitionStructure{}
+	if err := ds.LoadDefinitions(filePath); err != nil {
+		t.Fatalf("Failed to load definition file: %v", err)
+	}
+	expected := map[string]string{
+		"PackResource":      "dark/a.png",
+		"ExplicitResource":  "dark/b-dark.png",
+		"LightOnlyResource": "",
+	}
+	for name, path := range expected {
+		if got := ds.Definitions[name].DarkCacheFilePath; got != path {
+			t.Errorf("Expected dark icon path %q for %s, got %q", path, name, got)
+		}
+	}
+}
@@ -0,0 +1,89 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package font
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// Extensions of the font files found in font directories
+var Extensions = []string{".ttf", ".otf", ".ttc"}
+
+func isFontFile(path string) bool {
+	ext := strings.ToLower(filepath.Ext(path))
+	for _, e := range Extensions {
+		if ext == e {
+			return true
+		}
+	}
+	return false
+}
+
+// Discover returns the font files in the directories and their subdirectories, sorted by path
+// within each directory
+func Discover(dirs []string) []string {
+	files := []string{}
+	for _, dir := range dirs {
+		found := []string{}
+		err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if !d.IsDir() && isFontFile(path) {
+				found = append(found, path)
+			}
+			return nil
+		})
+		if err != nil {
+			log.Warnf("Failed to read font directory %s: %v", dir, err)
+		}
+		sort.Strings(found)
+		files = append(files, found...)
+	}
+	return files
+}
+
+// Find returns the path of a font given by path, or by file name with or without extension
+// in the directories or among files, the fonts found in them by Discover. It returns name when
+// the font is not found.
+func Find(name string, dirs []string, files []string) string {
+	if name == "" || name == "goregular" || filepath.IsAbs(name) {
+		return name
+	}
+	if _, err := os.Stat(name); err == nil {
+		return name
+	}
+	for _, dir := range dirs {
+		for _, candidate := range append([]string{name}, withExtensions(name)...) {
+			path := filepath.Join(dir, candidate)
+			if _, err := os.Stat(path); err == nil {
+				return path
+			}
+		}
+	}
+	for _, path := range files {
+		base := filepath.Base(path)
+		if base == name || strings.TrimSuffix(base, filepath.Ext(base)) == name {
+			return path
+		}
+	}
+	return name
+}
+
+func withExtensions(name string) []string {
+	if isFontFile(name) {
+		return nil
+	}
+	names := []string{}
+	for _, ext := range Extensions {
+		names = append(names, name+ext)
+	}
+	return names
+}
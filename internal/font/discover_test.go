@@ -0,0 +1,46 @@
+package font
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestDiscoverAndFind(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, "noto"), 0755); err != nil {
+		t.Fatalf("Failed to create directory: %v", err)
+	}
+	for _, name := range []string{"b.ttf", "A.OTF", "noto/NotoSansCJK-Regular.ttc", "readme.txt"} {
+		if err := os.WriteFile(filepath.Join(dir, name), nil, 0644); err != nil {
+			t.Fatalf("Failed to write file: %v", err)
+		}
+	}
+
+	expected := []string{
+		filepath.Join(dir, "A.OTF"),
+		filepath.Join(dir, "b.ttf"),
+		filepath.Join(dir, "noto", "NotoSansCJK-Regular.ttc"),
+	}
+	files := Discover([]string{dir})
+	if !reflect.DeepEqual(files, expected) {
+		t.Errorf("Expected %v, got %v", expected, files)
+	}
+
+	tests := []struct {
+		name     string
+		expected string
+	}{
+		{"b.ttf", filepath.Join(dir, "b.ttf")},
+		{"b", filepath.Join(dir, "b.ttf")},
+		{"NotoSansCJK-Regular", filepath.Join(dir, "noto", "NotoSansCJK-Regular.ttc")},
+		{"missing.ttf", "missing.ttf"},
+		{"goregular", "goregular"},
+	}
+	for _, tt := range tests {
+		if path := Find(tt.name, []string{dir}, files); path != tt.expected {
+			t.Errorf("Find(%s): expected %s, got %s", tt.name, tt.expected, path)
+		}
+	}
+}
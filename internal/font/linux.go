@@ -15,3 +15,15 @@ var Paths = []string{
 	"/run/current-system/sw/share/X11/fonts/LiberationSans-Regular.ttf", // For NixOS Linux liberation_ttf package (enable fontDir in fonts options).
 	"goregular", // As the default font, uses golang.org/x/image/font/gofont/goregular. For more information about this font, go to: https://go.dev/blog/go-fonts
 }
+
+// FallbackPaths are fonts drawing the characters missing from the title font, such as
+// Chinese, Japanese and Korean characters and symbols. Fonts not installed are skipped.
+var FallbackPaths = []string{
+	"/usr/share/fonts/opentype/noto/NotoSansCJK-Regular.ttc",      // For Ubuntu/Debian Linux fonts-noto-cjk package.
+	"/usr/share/fonts/google-noto-cjk/NotoSansCJK-Regular.ttc",    // For Fedora Linux google-noto-sans-cjk-ttc-fonts package.
+	"/usr/share/fonts/noto-cjk/NotoSansCJK-Regular.ttc",           // For Arch Linux noto-fonts-cjk package.
+	"/usr/share/fonts/truetype/droid/DroidSansFallbackFull.ttf",   // For Ubuntu/Debian Linux fonts-droid-fallback package.
+	"/usr/share/fonts/wenquanyi/wqy-zenhei/wqy-zenhei.ttc",        // For Alpine/Arch Linux font-wqy-zenhei package.
+	"/usr/share/fonts/truetype/dejavu/DejaVuSans.ttf",             // For Ubuntu/Debian Linux fonts-dejavu-core package.
+	"/usr/share/fonts/truetype/noto/NotoSansSymbols2-Regular.ttf", // For Ubuntu/Debian Linux fonts-noto-core package.
+}
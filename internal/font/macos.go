@@ -10,3 +10,13 @@ var Paths = []string{
 	"/Library/Fonts/Arial Unicode.ttf",
 	"goregular", // As the default font, uses golang.org/x/image/font/gofont/goregular. For more information about this font, go to: https://go.dev/blog/go-fonts
 }
+
+// FallbackPaths are fonts drawing the characters missing from the title font, such as
+// Chinese, Japanese and Korean characters and symbols. Fonts not installed are skipped.
+var FallbackPaths = []string{
+	"/System/Library/Fonts/ヒラギノ角ゴシック W3.ttc",
+	"/System/Library/Fonts/Hiragino Sans GB.ttc",
+	"/System/Library/Fonts/AppleSDGothicNeo.ttc",
+	"/Library/Fonts/Arial Unicode.ttf",
+	"/System/Library/Fonts/Apple Symbols.ttf",
+}
@@ -10,3 +10,14 @@ var Paths = []string{
 	"C:\\Windows\\Fonts\\arial.ttf",
 	"goregular", // As the default font, uses golang.org/x/image/font/gofont/goregular. For more information about this font, go to: https://go.dev/blog/go-fonts
 }
+
+// FallbackPaths are fonts drawing the characters missing from the title font, such as
+// Chinese, Japanese and Korean characters and symbols. Fonts not installed are skipped.
+var FallbackPaths = []string{
+	"C:\\Windows\\Fonts\\YuGothM.ttc",
+	"C:\\Windows\\Fonts\\msgothic.ttc",
+	"C:\\Windows\\Fonts\\msyh.ttc",
+	"C:\\Windows\\Fonts\\malgun.ttf",
+	"C:\\Windows\\Fonts\\seguisym.ttf",
+	"C:\\Windows\\Fonts\\seguiemj.ttf",
+}
@@ -0,0 +1,168 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package types
+
+import (
+	"image"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// WINDROSE_NEAREST attaches a link to the point of the resource border nearest to the
+// other end of the link. It is resolved to a side (N, E, S or W) and a point on it.
+const WINDROSE_NEAREST Windrose = -2
+
+// Distance kept between an attachment point and the corners of the border
+const attachmentCornerMargin = 10
+
+// ConvertLinkPosition converts the position of a link end, which accepts "nearest"
+// in addition to the windrose positions and "auto"
+func ConvertLinkPosition(position string) (Windrose, error) {
+	if position == "nearest" {
+		return WINDROSE_NEAREST, nil
+	}
+	return ConvertWindrose(position)
+}
+
+// resolveNearestPositions resolves WINDROSE_NEAREST positions to a side and a point of the border.
+// Windrose positions of the other end must be resolved beforehand. When both ends are nearest,
+// the target is attached towards the center of the source, then the source towards the target,
+// and finally the target again towards the attached source.
+func (l *Link) resolveNearestPositions() {
+	sourceNearest := l.SourcePosition == WINDROSE_NEAREST
+	targetNearest := l.TargetPosition == WINDROSE_NEAREST
+
+	attachTarget := func(ref image.Point) {
+		pt, side := nearestBorderPoint(attachmentBorder(l.Target), l.Target.headerRect(), ref)
+		l.TargetPosition = side
+		l.targetAttachment = &pt
+	}
+	if targetNearest {
+		ref := resourceCenter(l.Source)
+		if !sourceNearest {
+			ref, _ = calcPosition(l.Source.GetBindings(), l.SourcePosition)
+		}
+		attachTarget(ref)
+	}
+	if sourceNearest {
+		ref, _ := calcPosition(l.Target.GetBindings(), l.TargetPosition)
+		if l.targetAttachment != nil {
+			ref = *l.targetAttachment
+		}
+		pt, side := nearestBorderPoint(attachmentBorder(l.Source), l.Source.headerRect(), ref)
+		l.SourcePosition = side
+		l.sourceAttachment = &pt
+		log.Infof("Attach link source to %v on side %v", pt, side)
+		if targetNearest {
+			attachTarget(pt)
+		}
+	}
+	if targetNearest {
+		log.Infof("Attach link target to %v on side %v", *l.targetAttachment, l.TargetPosition)
+	}
+}
+
+// resourceCenter returns the center of the bindings of a resource
+func resourceCenter(r *Resource) image.Point {
+	b := r.GetBindings()
+	return image.Point{(b.Min.X + b.Max.X) / 2, (b.Min.Y + b.Max.Y) / 2}
+}
+
+// attachmentBorder returns the rectangle links attach to: the frame of a group,
+// or the icon and the title below it for other resources
+func attachmentBorder(r *Resource) image.Rectangle {
+	if len(r.children) > 0 {
+		return r.GetBindings()
+	}
+	return routingObstacle(r)
+}
+
+// headerRect returns the area of the icon and title in the header of a group,
+// or an empty rectangle for resources without children
+func (r *Resource) headerRect() image.Rectangle {
+	if len(r.children) == 0 || r.bindings == nil {
+		return image.Rectangle{}
+	}
+	textWidth, textHeight := 0, 0
+	if face, err := r.prepareFontFace(true, r.parent); err == nil {
+		textWidth, textHeight = r.calculateTitleSize(face)
+	}
+	b := r.GetBindings()
+	width := r.iconBounds.Dx() + textWidth
+	height := maxInt(r.iconBounds.Dy(), textHeight)
+	switch r.headerAlign {
+	case "center":
+		width = maxInt(r.iconBounds.Dx(), textWidth)
+		height = r.iconBounds.Dy() + textHeight
+		return image.Rect(b.Min.X+(b.Dx()-width)/2, b.Min.Y, b.Min.X+(b.Dx()+width)/2, b.Min.Y+height)
+	case "right":
+		return image.Rect(b.Max.X-width, b.Min.Y, b.Max.X, b.Min.Y+height)
+	}
+	return image.Rect(b.Min.X, b.Min.Y, b.Min.X+width, b.Min.Y+height)
+}
+
+// nearestBorderPoint returns the point of the border nearest to ref and the side it is on.
+// Points near the corners and next to the header are not used, so that the link slides along
+// the border instead of converging on a windrose point or running into the header.
+func nearestBorderPoint(border, header image.Rectangle, ref image.Point) (image.Point, Windrose) {
+	type side struct {
+		position   Windrose
+		horizontal bool // The side runs along the X axis
+		fixed      int  // Coordinate of the side on the other axis
+		min, max   int  // Range of the side
+		exclude    [2]int
+	}
+	m := attachmentCornerMargin
+	sides := []side{
+		{WINDROSE_N, true, border.Min.Y, border.Min.X + m, border.Max.X - m, [2]int{}},
+		{WINDROSE_E, false, border.Max.X, border.Min.Y + m, border.Max.Y - m, [2]int{}},
+		{WINDROSE_S, true, border.Max.Y, border.Min.X + m, border.Max.X - m, [2]int{}},
+		{WINDROSE_W, false, border.Min.X, border.Min.Y + m, border.Max.Y - m, [2]int{}},
+	}
+	if !header.Empty() {
+		// The header touches the top side, and the left or right side depending on its alignment
+		sides[0].exclude = [2]int{header.Min.X - m, header.Max.X + m}
+		if header.Max.X >= border.Max.X {
+			sides[1].exclude = [2]int{header.Min.Y - m, header.Max.Y + m}
+		}
+		if header.Min.X <= border.Min.X {
+			sides[3].exclude = [2]int{header.Min.Y - m, header.Max.Y + m}
+		}
+	}
+
+	best := image.Point{}
+	bestSide := WINDROSE_N
+	bestDistance := -1
+	consider := func(s side, lo, hi int) {
+		if lo > hi {
+			return
+		}
+		var pt image.Point
+		if s.horizontal {
+			pt = image.Point{min(max(ref.X, lo), hi), s.fixed}
+		} else {
+			pt = image.Point{s.fixed, min(max(ref.Y, lo), hi)}
+		}
+		d := pt.Sub(ref)
+		distance := d.X*d.X + d.Y*d.Y
+		if bestDistance < 0 || distance < bestDistance {
+			best, bestSide, bestDistance = pt, s.position, distance
+		}
+	}
+	for _, s := range sides {
+		if s.exclude == [2]int{} {
+			consider(s, s.min, s.max)
+			continue
+		}
+		consider(s, s.min, min(s.max, s.exclude[0]))
+		consider(s, max(s.min, s.exclude[1]), s.max)
+	}
+	if bestDistance < 0 {
+		// The border is too small to avoid the header: fall back to the middle of the nearest side
+		for _, s := range sides {
+			consider(s, (s.min+s.max)/2, (s.min+s.max)/2)
+		}
+	}
+	return best, bestSide
+}
@@ -0,0 +1,204 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package types
+
+import (
+	"image"
+	"image/color"
+	"math"
+
+	"github.com/awslabs/diagram-as-code/internal/vector"
+	log "github.com/sirupsen/logrus"
+	"golang.org/x/image/font"
+	"golang.org/x/image/math/fixed"
+)
+
+const (
+	badgeFontSize  = 13.0
+	badgeHeight    = 20.0 // Height of a badge with text
+	badgeDotRadius = 6.0  // Radius of a badge without text
+	badgePadding   = 6.0  // Space between the text and the ends of a badge
+	badgeOutline   = 2.0  // Width of the outline separating a badge from the icon
+)
+
+// Badge is a small pill drawn over a corner or side of the icon, such as a counter or a status dot.
+// A badge without text is drawn as a dot.
+type Badge struct {
+	Text     string
+	Color    color.RGBA
+	Position Windrose // N, NE, E, SE, S, SW, W or NW of the icon
+}
+
+func (r *Resource) AddBadge(badge Badge) {
+	r.badges = append(r.badges, badge)
+}
+
+func (r *Resource) GetBadges() []Badge {
+	return r.badges
+}
+
+// iconRect returns the rectangle the icon is drawn in
+func (r *Resource) iconRect() image.Rectangle {
+	x := image.Rectangle{r.bindings.Min, r.bindings.Min.Add(image.Point{64, 64})}
+	switch r.headerAlign {
+	case "center":
+		x = x.Add(image.Point{(r.bindings.Dx() - 64) / 2, 0})
+	case "right":
+		x = x.Add(image.Point{r.bindings.Dx() - 64, 0})
+	}
+	return x
+}
+
+// badgeAnchor returns the rectangle badges are placed on: the icon, or the bindings of resources without icon
+func (r *Resource) badgeAnchor() image.Rectangle {
+	if r.iconImage.Bounds().Dx() == 0 {
+		return *r.bindings
+	}
+	return r.iconRect()
+}
+
+// size returns the width and height of the badge
+func (b Badge) size(face font.Face) vector.Vector {
+	if b.Text == "" {
+		return vector.New(badgeDotRadius*2, badgeDotRadius*2)
+	}
+	width := float64(font.MeasureString(face, b.Text).Ceil()) + badgePadding*2
+	return vector.New(math.Max(width, badgeHeight), badgeHeight)
+}
+
+// badgeCenter returns the center of a badge on the corner or side of rect given by position
+func badgeCenter(rect image.Rectangle, position Windrose) vector.Vector {
+	x := float64(rect.Min.X+rect.Max.X) / 2
+	y := float64(rect.Min.Y+rect.Max.Y) / 2
+	switch position {
+	case WINDROSE_NW, WINDROSE_W, WINDROSE_SW:
+		x = float64(rect.Min.X)
+	case WINDROSE_NE, WINDROSE_E, WINDROSE_SE:
+		x = float64(rect.Max.X)
+	}
+	switch position {
+	case WINDROSE_NW, WINDROSE_N, WINDROSE_NE:
+		y = float64(rect.Min.Y)
+	case WINDROSE_SW, WINDROSE_S, WINDROSE_SE:
+		y = float64(rect.Max.Y)
+	}
+	return vector.New(x, y)
+}
+
+// badgePlacement is a badge and the rectangle it is placed on
+type badgePlacement struct {
+	badge  Badge
+	anchor image.Rectangle
+}
+
+// placedBadges returns the count label on the stack of icons, then the badges on the front icon
+func (r *Resource) placedBadges() []badgePlacement {
+	placements := []badgePlacement{}
+	if badge, ok := r.countBadge(); ok {
+		placements = append(placements, badgePlacement{badge, r.stackRect()})
+	}
+	anchor := r.badgeAnchor()
+	for _, b := range r.badges {
+		placements = append(placements, badgePlacement{b, anchor})
+	}
+	return placements
+}
+
+// iconOverhang returns how far the stacked copies of the icon and the badges extend beyond
+// the icon on each side
+func (r *Resource) iconOverhang() Margin {
+	m := Margin{}
+	if copies := r.stackCopies(); copies > 1 {
+		m.Top = (copies - 1) * stackedOffset
+		m.Right = (copies - 1) * stackedOffset
+	}
+	placements := r.placedBadges()
+	if len(placements) == 0 {
+		return m
+	}
+	face, err := loadFontFace("", badgeFontSize, FONT_WEIGHT_BOLD)
+	if err != nil {
+		log.Warnf("Failed to prepare font face for badges: %v", err)
+		return m
+	}
+	anchor := r.badgeAnchor()
+	for _, p := range placements {
+		size := p.badge.size(face)
+		center := badgeCenter(p.anchor, p.badge.Position)
+		half := size.Scale(0.5).Add(vector.New(badgeOutline, badgeOutline))
+		m.Top = maxInt(m.Top, int(math.Ceil(float64(anchor.Min.Y)-(center.Y-half.Y))))
+		m.Right = maxInt(m.Right, int(math.Ceil(center.X+half.X-float64(anchor.Max.X))))
+		m.Bottom = maxInt(m.Bottom, int(math.Ceil(center.Y+half.Y-float64(anchor.Max.Y))))
+		m.Left = maxInt(m.Left, int(math.Ceil(float64(anchor.Min.X)-(center.X-half.X))))
+	}
+	return m
+}
+
+// drawBadges draws the badges over the corners and sides of the icon
+func (r *Resource) drawBadges(img *image.RGBA) {
+	placements := r.placedBadges()
+	if len(placements) == 0 {
+		return
+	}
+	face, err := loadFontFace("", badgeFontSize, FONT_WEIGHT_BOLD)
+	if err != nil {
+		log.Warnf("Failed to prepare font face for badges: %v", err)
+		return
+	}
+	for _, p := range placements {
+		b := p.badge
+		size := b.size(face)
+		center := badgeCenter(p.anchor, b.Position)
+		radius := size.Y / 2
+		a := center.Sub(vector.New(size.X/2-radius, 0))
+		c := center.Add(vector.New(size.X/2-radius, 0))
+		fillCapsule(img, a, c, radius+badgeOutline, color.RGBA{255, 255, 255, 255})
+		fillCapsule(img, a, c, radius, b.Color)
+		if b.Text == "" {
+			continue
+		}
+		textColor := color.RGBA{255, 255, 255, 255}
+		if 0.299*float64(b.Color.R)+0.587*float64(b.Color.G)+0.114*float64(b.Color.B) > 160 {
+			textColor = color.RGBA{0, 0, 0, 255}
+		}
+		metrics := face.Metrics()
+		width := font.MeasureString(face, b.Text)
+		d := &font.Drawer{
+			Dst:  img,
+			Src:  image.NewUniform(textColor),
+			Face: face,
+			Dot: fixed.Point26_6{
+				X: fixed.Int26_6(center.X*64) - width/2,
+				Y: fixed.Int26_6(center.Y*64) + (metrics.Ascent-metrics.Descent)/2,
+			},
+		}
+		d.DrawString(b.Text)
+	}
+}
+
+// fillCapsule draws an antialiased filled capsule: the points within radius of the segment a-b
+func fillCapsule(img *image.RGBA, a, b vector.Vector, radius float64, fill color.RGBA) {
+	bounds := image.Rect(
+		int(math.Floor(math.Min(a.X, b.X)-radius-1)), int(math.Floor(math.Min(a.Y, b.Y)-radius-1)),
+		int(math.Ceil(math.Max(a.X, b.X)+radius+1)), int(math.Ceil(math.Max(a.Y, b.Y)+radius+1)),
+	).Intersect(img.Bounds())
+	ab := b.Sub(a)
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			p := vector.New(float64(x), float64(y))
+			t := 0.0
+			if l := ab.Dot(ab); l > 0 {
+				t = math.Min(math.Max(p.Sub(a).Dot(ab)/l, 0), 1)
+			}
+			d := p.Sub(a.Add(ab.Scale(t))).Length()
+			coverage := math.Min(math.Max(radius+0.5-d, 0), 1)
+			if coverage == 0 {
+				continue
+			}
+			c := fill
+			c.A = uint8(float64(fill.A) * coverage)
+			img.Set(x, y, _blend_color(img.At(x, y), c))
+		}
+	}
+}
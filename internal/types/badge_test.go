@@ -0,0 +1,62 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package types
+
+import (
+	"image"
+	"image/color"
+	"testing"
+)
+
+func TestBadges(t *testing.T) {
+	red := color.RGBA{209, 50, 18, 255}
+	newResource := func(badges ...Badge) *Resource {
+		r := new(Resource).Init()
+		r.iconImage = image.NewRGBA(image.Rect(0, 0, 64, 64))
+		r.iconBounds = image.Rect(0, 0, 64, 64)
+		r.label = "App servers"
+		for _, b := range badges {
+			r.AddBadge(b)
+		}
+		return r
+	}
+
+	plain := newResource()
+	badged := newResource(Badge{Text: "×3", Color: red, Position: WINDROSE_NE}, Badge{Color: red, Position: WINDROSE_SW})
+	for _, r := range []*Resource{plain, badged} {
+		if err := r.Scale(nil, nil); err != nil {
+			t.Fatalf("Scale failed: %v", err)
+		}
+	}
+	overhang := badged.iconOverhang()
+	if overhang.Top <= 0 || overhang.Right <= 0 || overhang.Bottom <= 0 || overhang.Left <= 0 {
+		t.Errorf("Expected badges on the corners to stick out on every side, got %+v", overhang)
+	}
+	pm, bm := plain.GetMargin(), badged.GetMargin()
+	if bm.Top != pm.Top+overhang.Top || bm.Bottom != pm.Bottom+overhang.Bottom {
+		t.Errorf("Expected the margin to include the badges, got %+v (without badges %+v)", bm, pm)
+	}
+
+	// Margins given by a preset keep room for the badges too, once
+	empty := newResource(Badge{Text: "×3", Color: red, Position: WINDROSE_NE})
+	empty.SetMargin(Margin{})
+	for range 2 {
+		if err := empty.Scale(nil, nil); err != nil {
+			t.Fatalf("Scale failed: %v", err)
+		}
+	}
+	if m, o := empty.GetMargin(), empty.iconOverhang(); m.Top != o.Top || m.Right != o.Right {
+		t.Errorf("Expected the preset margin to grow by the badge overhang %+v once, got %+v", o, m)
+	}
+
+	img := image.NewRGBA(image.Rect(0, 0, 100, 100))
+	badged.bindings = &image.Rectangle{image.Point{20, 20}, image.Point{84, 84}}
+	badged.drawIcon(img)
+	if c := img.RGBAAt(20, 84); c != red {
+		t.Errorf("Expected a dot on the bottom-left corner of the icon, got %v", c)
+	}
+	if c := img.RGBAAt(84, 20); c == (color.RGBA{}) {
+		t.Error("Expected a badge on the top-right corner of the icon")
+	}
+}
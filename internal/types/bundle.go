@@ -0,0 +1,356 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package types
+
+import (
+	"image"
+	"sort"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// Distance between the lines of bundles running in opposite directions in the same group
+const bundleReverseOffset = 10
+
+// linkBundle is the set of orthogonal links running in the same direction between the
+// children of a group with Bundling. Each link joins a collector line in the gap next to its
+// source, runs along the trunk shared by the bundle across the gaps in between, and leaves
+// through the collector line in the gap next to its target. Links between adjacent children
+// only use the collector line of that gap.
+type linkBundle struct {
+	lca     *Resource
+	forward bool    // Links run from the first children of the group to the last ones
+	links   []*Link // Links of the bundle, in drawing order of the resources
+	trunk   int     // Coordinate of the trunk: Y in horizontal groups and X in vertical ones
+}
+
+type linkBundleKey struct {
+	lca     *Resource
+	forward bool
+}
+
+// Bundles of the diagram being drawn, built when their first link is drawn
+var linkBundles = map[linkBundleKey]*linkBundle{}
+
+// bundleEnds returns the group bundling the link and the indices of the children of the
+// group holding its source and target. ok is false when the link is not bundled: it is not a
+// plain orthogonal link, or it does not leave its source and enter its target along the
+// direction of the group.
+func (l *Link) bundleEnds() (lca *Resource, sourceIndex, targetIndex int, ok bool) {
+	if l.Type != "orthogonal" || len(l.Waypoints) > 0 || l.Target == nil || l.IsJunction() {
+		return nil, 0, 0, false
+	}
+	lca = findLowestCommonAncestor(l.Source, l.Target)
+	if lca == nil || !lca.isBundling() || (lca.direction != "horizontal" && lca.direction != "vertical") {
+		return nil, 0, 0, false
+	}
+	sourceChild := findChildAncestorInLCA(lca, l.Source)
+	targetChild := findChildAncestorInLCA(lca, l.Target)
+	sourceIndex, targetIndex = -1, -1
+	for i, child := range lca.children {
+		if child == sourceChild {
+			sourceIndex = i
+		}
+		if child == targetChild {
+			targetIndex = i
+		}
+	}
+	if sourceIndex == -1 || targetIndex == -1 || sourceIndex == targetIndex {
+		return nil, 0, 0, false
+	}
+
+	// The source must face the target side, and the target the source side
+	sign := 1.0
+	if sourceIndex > targetIndex {
+		sign = -1
+	}
+	sourceDir := l.getDirectionVector(int(l.SourcePosition))
+	targetDir := l.getDirectionVector(int(l.TargetPosition))
+	if lca.direction == "horizontal" {
+		ok = sourceDir.X*sign > 0.5 && targetDir.X*sign < -0.5
+	} else {
+		ok = sourceDir.Y*sign > 0.5 && targetDir.Y*sign < -0.5
+	}
+	return lca, sourceIndex, targetIndex, ok
+}
+
+// bundleFor returns the bundle of the link, built with every bundled link of the group
+// running in the same direction
+func bundleFor(lca *Resource, forward bool) *linkBundle {
+	key := linkBundleKey{lca, forward}
+	if b, ok := linkBundles[key]; ok {
+		return b
+	}
+	b := &linkBundle{lca: lca, forward: forward}
+	seen := map[*Link]bool{}
+	var walk func(r *Resource)
+	walk = func(r *Resource) {
+		for _, link := range r.links {
+			if seen[link] {
+				continue
+			}
+			seen[link] = true
+			if linkLCA, s, t, ok := link.bundleEnds(); ok && linkLCA == lca && (s < t) == forward {
+				b.links = append(b.links, link)
+			}
+		}
+		for _, child := range r.children {
+			walk(child)
+		}
+		for _, bc := range r.borderChildren {
+			walk(bc.Resource)
+		}
+	}
+	walk(lca)
+	b.trunk = b.routeTrunk()
+	log.Infof("Bundle of %d link(s) in %s with the trunk at %d", len(b.links), getResourceName(lca), b.trunk)
+	linkBundles[key] = b
+	return b
+}
+
+// along returns the coordinate of the point along the direction of the group
+func (b *linkBundle) along(p image.Point) int {
+	if b.lca.direction == "horizontal" {
+		return p.X
+	}
+	return p.Y
+}
+
+// across returns the coordinate of the point across the direction of the group
+func (b *linkBundle) across(p image.Point) int {
+	if b.lca.direction == "horizontal" {
+		return p.Y
+	}
+	return p.X
+}
+
+// point returns the point at the coordinates along and across the direction of the group
+func (b *linkBundle) point(along, across int) image.Point {
+	if b.lca.direction == "horizontal" {
+		return image.Point{along, across}
+	}
+	return image.Point{across, along}
+}
+
+// collector returns the coordinate of the collector line in the gap between the children
+// i and i+1 of the group. Bundles running backwards use a line next to it.
+func (b *linkBundle) collector(i int) int {
+	before := b.lca.children[i].GetBindings()
+	after := b.lca.children[i+1].GetBindings()
+	c := (b.along(before.Max) + b.along(after.Min)) / 2
+	if !b.forward {
+		c += bundleReverseOffset
+	}
+	return c
+}
+
+// collectors returns the collector lines next to the source and the target of a link
+// between the children at sourceIndex and targetIndex
+func (b *linkBundle) collectors(sourceIndex, targetIndex int) (int, int) {
+	if sourceIndex < targetIndex {
+		return b.collector(sourceIndex), b.collector(targetIndex - 1)
+	}
+	return b.collector(sourceIndex - 1), b.collector(targetIndex)
+}
+
+// routeTrunk places the trunk at the middle of the sources and targets of the bundle, moved
+// to the nearest position clear of the resources of the children the trunk runs across
+func (b *linkBundle) routeTrunk() int {
+	if len(b.links) == 0 {
+		return 0
+	}
+	sum := 0
+	for _, link := range b.links {
+		sum += b.across(centerOf(link.Source.GetBindings())) + b.across(centerOf(link.Target.GetBindings()))
+	}
+	middle := sum / (2 * len(b.links))
+
+	// Children strictly between the source and target of a link are crossed by the trunk
+	crossed := map[*Resource]bool{}
+	for _, link := range b.links {
+		_, s, t, _ := link.bundleEnds()
+		for i := min(s, t) + 1; i < max(s, t); i++ {
+			crossed[b.lca.children[i]] = true
+		}
+	}
+	obstacles := []image.Rectangle{}
+	var walk func(r *Resource)
+	walk = func(r *Resource) {
+		if len(r.children) == 0 && r.bindings != nil {
+			if o := routingObstacle(r); !o.Empty() {
+				obstacles = append(obstacles, o.Inset(-routingObstacleMargin))
+			}
+		}
+		for _, child := range r.children {
+			walk(child)
+		}
+	}
+	for _, child := range b.lca.children {
+		if crossed[child] {
+			walk(child)
+		}
+	}
+
+	candidates := []int{middle}
+	for _, o := range obstacles {
+		candidates = append(candidates, b.across(o.Min)-1, b.across(o.Max)+1)
+	}
+	// Keep clear of the trunk of the bundle running the other way
+	other := linkBundles[linkBundleKey{b.lca, !b.forward}]
+	if other != nil {
+		candidates = append(candidates, other.trunk-bundleReverseOffset, other.trunk+bundleReverseOffset)
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return abs(candidates[i]-middle) < abs(candidates[j]-middle)
+	})
+	for _, c := range candidates {
+		free := other == nil || abs(c-other.trunk) >= bundleReverseOffset
+		for _, o := range obstacles {
+			if c >= b.across(o.Min) && c <= b.across(o.Max) {
+				free = false
+				break
+			}
+		}
+		if free {
+			return c
+		}
+	}
+	return middle
+}
+
+// centerOf returns the center of the rectangle
+func centerOf(r image.Rectangle) image.Point {
+	return image.Point{(r.Min.X + r.Max.X) / 2, (r.Min.Y + r.Max.Y) / 2}
+}
+
+// bundledControlPoints returns the control points of a bundled link: from the source to the
+// collector line next to it, along the trunk, and from the collector line next to the target
+// to the target. ok is false when the link is not bundled.
+func (l *Link) bundledControlPoints(sourcePt, targetPt image.Point) ([]image.Point, bool) {
+	lca, sourceIndex, targetIndex, ok := l.bundleEnds()
+	if !ok {
+		return nil, false
+	}
+	b := bundleFor(lca, sourceIndex < targetIndex)
+	sourceCollector, targetCollector := b.collectors(sourceIndex, targetIndex)
+
+	path := []image.Point{sourcePt, b.point(sourceCollector, b.across(sourcePt))}
+	if sourceCollector != targetCollector {
+		path = append(path, b.point(sourceCollector, b.trunk), b.point(targetCollector, b.trunk))
+	}
+	path = append(path, b.point(targetCollector, b.across(targetPt)), targetPt)
+	path = mergeCollinearPoints(path)
+	if len(path) < 2 {
+		return nil, true
+	}
+	log.Infof("Bundled path of %s -> %s: %v", getResourceName(l.Source), getResourceName(l.Target), path)
+	return path[1 : len(path)-1], true
+}
+
+// bundleBranchPoints returns the bends of the paths where lines go in three or four
+// directions, which is where links join or leave the lines they share with other links
+func bundleBranchPoints(paths [][]image.Point) []image.Point {
+	type segment struct{ a, b image.Point }
+	segments := []segment{}
+	for _, path := range paths {
+		path = mergeCollinearPoints(path)
+		for i := 0; i+1 < len(path); i++ {
+			segments = append(segments, segment{path[i], path[i+1]})
+		}
+	}
+	// sign returns -1, 0 or 1
+	sign := func(v int) int {
+		switch {
+		case v > 0:
+			return 1
+		case v < 0:
+			return -1
+		}
+		return 0
+	}
+	onSegment := func(p image.Point, s segment) bool {
+		return p.X >= min(s.a.X, s.b.X) && p.X <= max(s.a.X, s.b.X) &&
+			p.Y >= min(s.a.Y, s.b.Y) && p.Y <= max(s.a.Y, s.b.Y) &&
+			(s.a.X == s.b.X || s.a.Y == s.b.Y)
+	}
+
+	points := []image.Point{}
+	found := map[image.Point]bool{}
+	for _, path := range paths {
+		path = mergeCollinearPoints(path)
+		if len(path) < 3 {
+			continue
+		}
+		for _, p := range path[1 : len(path)-1] {
+			if found[p] {
+				continue
+			}
+			directions := map[image.Point]bool{}
+			for _, s := range segments {
+				if !onSegment(p, s) {
+					continue
+				}
+				for _, end := range []image.Point{s.a, s.b} {
+					if end != p {
+						directions[image.Point{sign(end.X - p.X), sign(end.Y - p.Y)}] = true
+					}
+				}
+			}
+			if len(directions) >= 3 {
+				found[p] = true
+				points = append(points, p)
+			}
+		}
+	}
+	return points
+}
+
+// DrawBundleJunctions draws a dot where bundled links join or leave the lines they share
+func DrawBundleJunctions(img *image.RGBA) {
+	keys := make([]linkBundleKey, 0, len(linkBundles))
+	for key := range linkBundles {
+		keys = append(keys, key)
+	}
+	// Bundles are drawn in a stable order so that overlapping dots do not change between runs
+	sort.Slice(keys, func(i, j int) bool {
+		a, b := keys[i].lca.GetBindings(), keys[j].lca.GetBindings()
+		if a.Min != b.Min {
+			return a.Min.Y < b.Min.Y || (a.Min.Y == b.Min.Y && a.Min.X < b.Min.X)
+		}
+		return keys[i].forward && !keys[j].forward
+	})
+	for _, key := range keys {
+		b := linkBundles[key]
+		paths := [][]image.Point{}
+		owners := []*Link{}
+		for _, link := range b.links {
+			if len(link.path) >= 2 {
+				paths = append(paths, link.path)
+				owners = append(owners, link)
+			}
+		}
+		if len(paths) < 2 {
+			continue
+		}
+		for _, p := range bundleBranchPoints(paths) {
+			// The dot takes the color of the first link bending there
+			for i, path := range paths {
+				if containsPoint(mergeCollinearPoints(path), p) {
+					owners[i].drawJunctionDot(img, p)
+					break
+				}
+			}
+		}
+	}
+}
+
+// containsPoint reports whether the point is one of the points
+func containsPoint(points []image.Point, p image.Point) bool {
+	for _, q := range points {
+		if q == p {
+			return true
+		}
+	}
+	return false
+}
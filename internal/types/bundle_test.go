@@ -0,0 +1,79 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package types
+
+import (
+	"image"
+	"reflect"
+	"testing"
+)
+
+func TestBundling(t *testing.T) {
+	defer ResetConvergencePointSegments()
+
+	// Services, a cache and databases side by side, each in a column 100px wide
+	newResource := func(parent *Resource, bindings image.Rectangle) *Resource {
+		r := &Resource{parent: parent, bindings: &bindings, direction: "vertical"}
+		if parent != nil {
+			parent.children = append(parent.children, r)
+		}
+		return r
+	}
+	root := newResource(nil, image.Rect(0, 0, 500, 300))
+	root.direction = "horizontal"
+	services := newResource(root, image.Rect(0, 0, 100, 300))
+	middle := newResource(root, image.Rect(200, 0, 300, 300))
+	databases := newResource(root, image.Rect(400, 0, 500, 300))
+	s1 := newResource(services, image.Rect(20, 20, 84, 84))
+	s2 := newResource(services, image.Rect(20, 200, 84, 264))
+	cache := newResource(middle, image.Rect(220, 120, 284, 184))
+	d1 := newResource(databases, image.Rect(420, 20, 484, 84))
+	d2 := newResource(databases, image.Rect(420, 200, 484, 264))
+
+	newLink := func(source, target *Resource) *Link {
+		l := &Link{Source: source, SourcePosition: WINDROSE_E, Target: target, TargetPosition: WINDROSE_W, Type: "orthogonal"}
+		source.AddLink(l)
+		target.AddLink(l)
+		return l
+	}
+	links := []*Link{newLink(s1, d1), newLink(s2, d2), newLink(s2, cache)}
+	ends := [][2]image.Point{{{84, 52}, {420, 52}}, {{84, 232}, {420, 232}}, {{84, 232}, {220, 152}}}
+
+	if _, ok := links[0].bundledControlPoints(ends[0][0], ends[0][1]); ok {
+		t.Error("Expected links not to be bundled without Bundling")
+	}
+
+	root.SetBundling(true)
+	// The collector lines are at the middle of the gaps (X=150 and X=350), and the trunk
+	// across the cache column runs just below the cache instead of through it
+	expected := [][]image.Point{
+		{{150, 52}, {150, 193}, {350, 193}, {350, 52}},
+		{{150, 232}, {150, 193}, {350, 193}, {350, 232}},
+		{{150, 232}, {150, 152}},
+	}
+	paths := [][]image.Point{}
+	for i, l := range links {
+		controlPts, ok := l.bundledControlPoints(ends[i][0], ends[i][1])
+		if !ok {
+			t.Fatalf("Expected link %d to be bundled", i)
+		}
+		if !reflect.DeepEqual(controlPts, expected[i]) {
+			t.Errorf("Link %d: expected control points %v, got %v", i, expected[i], controlPts)
+		}
+		paths = append(paths, append(append([]image.Point{ends[i][0]}, controlPts...), ends[i][1]))
+	}
+
+	// Links split where the trunk meets the collector lines and where the link to the
+	// cache leaves the collector line next to the services
+	splits := []image.Point{{150, 193}, {350, 193}, {150, 152}}
+	if points := bundleBranchPoints(paths); !reflect.DeepEqual(points, splits) {
+		t.Errorf("Expected branch points %v, got %v", splits, points)
+	}
+
+	// Links facing away from the other end are not bundled
+	away := &Link{Source: d1, SourcePosition: WINDROSE_S, Target: s1, TargetPosition: WINDROSE_E, Type: "orthogonal"}
+	if _, ok := away.bundledControlPoints(image.Point{452, 84}, image.Point{84, 52}); ok {
+		t.Error("Expected a link leaving its source across the group not to be bundled")
+	}
+}
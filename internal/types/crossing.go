@@ -0,0 +1,195 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package types
+
+import (
+	"fmt"
+	"image"
+	"math"
+	"sort"
+
+	"github.com/awslabs/diagram-as-code/internal/vector"
+)
+
+const (
+	LINK_CROSSING_NONE = "none"
+	LINK_CROSSING_JUMP = "jump"
+	LINK_CROSSING_GAP  = "gap"
+)
+
+// Style of crossings between links, and the segments of the links drawn so far.
+// Links drawn later jump over (or leave a gap at) the links drawn before them.
+var linkCrossing = LINK_CROSSING_NONE
+var drawnLinkSegments []Segment
+
+// SetLinkCrossing sets how crossings between links are drawn: none, jump or gap
+func SetLinkCrossing(style string) error {
+	switch style {
+	case "", LINK_CROSSING_NONE:
+		linkCrossing = LINK_CROSSING_NONE
+	case LINK_CROSSING_JUMP, LINK_CROSSING_GAP:
+		linkCrossing = style
+	default:
+		return fmt.Errorf("unknown link crossing style %s (allowed: none, jump, gap)", style)
+	}
+	return nil
+}
+
+// ResetDrawnLinkSegments clears the segments used to detect link crossings
+func ResetDrawnLinkSegments() {
+	drawnLinkSegments = []Segment{}
+}
+
+// recordLinkSegments remembers the segments of a drawn path for later links
+func (l *Link) recordLinkSegments(path []image.Point) {
+	for i := 0; i < len(path)-1; i++ {
+		drawnLinkSegments = append(drawnLinkSegments, Segment{
+			X1: path[i].X, Y1: path[i].Y, X2: path[i+1].X, Y2: path[i+1].Y, Link: l,
+		})
+	}
+}
+
+// crossingRadius returns the half-length of the jump or gap at a crossing
+func (l *Link) crossingRadius() float64 {
+	return float64(5 + l.LineWidth)
+}
+
+// findCrossings returns the distances from a, along a->b, at which the segment crosses
+// segments of links drawn before. Crossings too close to either end are ignored so that
+// links sharing an endpoint or converging do not get jumps.
+func (l *Link) findCrossings(a, b image.Point) []float64 {
+	p := vector.New(float64(a.X), float64(a.Y))
+	d := vector.New(float64(b.X-a.X), float64(b.Y-a.Y))
+	length := d.Length()
+	radius := l.crossingRadius()
+	if length <= radius*2 {
+		return nil
+	}
+
+	crossings := []float64{}
+	for _, s := range drawnLinkSegments {
+		if s.Link == l {
+			continue
+		}
+		q := vector.New(float64(s.X1), float64(s.Y1))
+		e := vector.New(float64(s.X2-s.X1), float64(s.Y2-s.Y1))
+		denominator := d.Cross(e)
+		if math.Abs(denominator) < 1e-9 {
+			continue // Parallel or overlapping segments do not cross
+		}
+		qp := q.Sub(p)
+		t := qp.Cross(e) / denominator
+		u := qp.Cross(d) / denominator
+		if u <= 0 || u >= 1 {
+			continue
+		}
+		distance := t * length
+		if distance < radius || distance > length-radius {
+			continue
+		}
+		// Skip crossings near the ends of the other segment (e.g. at its bends)
+		otherLength := e.Length()
+		if u*otherLength < 1 || (1-u)*otherLength < 1 {
+			continue
+		}
+		crossings = append(crossings, distance)
+	}
+	sort.Float64s(crossings)
+	return crossings
+}
+
+// mergeCollinearPoints removes duplicated points and points in the middle of a straight run,
+// so that crossings are detected on whole segments rather than at intermediate points
+func mergeCollinearPoints(path []image.Point) []image.Point {
+	pts := []image.Point{}
+	for _, p := range path {
+		if len(pts) > 0 && pts[len(pts)-1] == p {
+			continue
+		}
+		if len(pts) >= 2 {
+			a, b := pts[len(pts)-2], pts[len(pts)-1]
+			ab := b.Sub(a)
+			bp := p.Sub(b)
+			// Same direction only; a path turning back keeps its bend
+			if ab.X*bp.Y == ab.Y*bp.X && ab.X*bp.X+ab.Y*bp.Y > 0 {
+				pts[len(pts)-1] = p
+				continue
+			}
+		}
+		pts = append(pts, p)
+	}
+	return pts
+}
+
+// drawPath draws a polyline with drawSegment
+func (l *Link) drawPath(img *image.RGBA, path []image.Point) {
+	pts := mergeCollinearPoints(path)
+	for i := 0; i < len(pts)-1; i++ {
+		l.drawSegment(img, pts[i], pts[i+1])
+	}
+}
+
+// drawSegment draws a straight segment of the link, with a jump or a gap where it crosses
+// links drawn before
+func (l *Link) drawSegment(img *image.RGBA, a, b image.Point) {
+	if linkCrossing == LINK_CROSSING_NONE {
+		l.drawLine(img, a, b)
+		return
+	}
+	crossings := l.findCrossings(a, b)
+	if len(crossings) == 0 {
+		l.drawLine(img, a, b)
+		return
+	}
+
+	start := vector.New(float64(a.X), float64(a.Y))
+	direction := vector.New(float64(b.X-a.X), float64(b.Y-a.Y)).Normalize()
+	radius := l.crossingRadius()
+
+	// Jumps bulge upwards, or to the left on vertical segments
+	normal := vector.New(direction.Y, -direction.X)
+	if normal.Y > 0 || (normal.Y == 0 && normal.X > 0) {
+		normal = normal.Scale(-1)
+	}
+
+	current := 0.0
+	for _, c := range crossings {
+		if c-radius < current {
+			// Crossings closer than a jump are merged into the previous one
+			if c+radius > current {
+				current = c + radius
+			}
+			continue
+		}
+		l.drawLine(img, toPoint(start.Add(direction.Scale(current))), toPoint(start.Add(direction.Scale(c-radius))))
+		if linkCrossing == LINK_CROSSING_JUMP {
+			l.drawArc(img, start.Add(direction.Scale(c)), direction, normal, radius)
+		}
+		current = c + radius
+	}
+	l.drawLine(img, toPoint(start.Add(direction.Scale(current))), b)
+}
+
+// drawArc draws a semicircle of the given radius around center, from the point behind
+// center along direction to the point ahead of it, bulging towards normal
+func (l *Link) drawArc(img *image.RGBA, center, direction, normal vector.Vector, radius float64) {
+	s := l.lineStroke()
+	if s.restart {
+		s.pattern = nil
+	}
+	steps := int(math.Ceil(math.Pi * radius))
+	for i := 0; i <= steps; i++ {
+		theta := math.Pi * float64(i) / float64(steps)
+		if !s.visible(l.dashDistance + radius*theta) {
+			continue
+		}
+		pos := center.Add(direction.Scale(-radius * math.Cos(theta))).Add(normal.Scale(radius * math.Sin(theta)))
+		radial := pos.Sub(center).Normalize()
+		for _, offset := range s.offsets {
+			finalPos := pos.Add(radial.Scale(offset))
+			l.drawNeighborsDot(img, finalPos.X, finalPos.Y)
+		}
+	}
+	l.dashDistance += math.Pi * radius
+}
@@ -0,0 +1,117 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package types
+
+import (
+	"image"
+	"math"
+
+	"github.com/awslabs/diagram-as-code/internal/vector"
+)
+
+const (
+	curveHandleRatio     = 0.4  // Length of the Bézier handles relative to the source-target distance
+	curveMinHandleLength = 30.0 // Minimum length of the Bézier handles
+)
+
+// calculateCurveControlPoints returns the two control points of the cubic Bézier curve.
+// The curve leaves the source and enters the target along their windrose directions.
+func (l *Link) calculateCurveControlPoints(sourcePt, targetPt image.Point) (vector.Vector, vector.Vector) {
+	sourceVec := vector.New(float64(sourcePt.X), float64(sourcePt.Y))
+	targetVec := vector.New(float64(targetPt.X), float64(targetPt.Y))
+	handle := math.Max(targetVec.Sub(sourceVec).Length()*curveHandleRatio, curveMinHandleLength)
+
+	c1 := sourceVec.Add(l.getDirectionVector(int(l.SourcePosition)).Scale(handle))
+	c2 := targetVec.Add(l.getDirectionVector(int(l.TargetPosition)).Scale(handle))
+	return c1, c2
+}
+
+// bezierPoint returns the point of the cubic Bézier curve at t
+func bezierPoint(p0, p1, p2, p3 vector.Vector, t float64) vector.Vector {
+	u := 1 - t
+	return p0.Scale(u * u * u).
+		Add(p1.Scale(3 * u * u * t)).
+		Add(p2.Scale(3 * u * t * t)).
+		Add(p3.Scale(t * t * t))
+}
+
+// bezierTangent returns the derivative of the cubic Bézier curve at t
+func bezierTangent(p0, p1, p2, p3 vector.Vector, t float64) vector.Vector {
+	u := 1 - t
+	return p1.Sub(p0).Scale(3 * u * u).
+		Add(p2.Sub(p1).Scale(6 * u * t)).
+		Add(p3.Sub(p2).Scale(3 * t * t))
+}
+
+// drawCurve draws a cubic Bézier curve, placing a dot every pixel of arc length
+// so that the line stroke matches drawLine.
+func (l *Link) drawCurve(img *image.RGBA, p0, p1, p2, p3 vector.Vector) {
+	// The control polygon is longer than the curve, so this oversamples the curve
+	polygon := p1.Sub(p0).Length() + p2.Sub(p1).Length() + p3.Sub(p2).Length()
+	steps := int(polygon*4) + 1
+
+	s := l.lineStroke()
+	prev := p0
+	travelled := 0.0
+	next := 0.0
+	for i := 0; i <= steps; i++ {
+		t := float64(i) / float64(steps)
+		pos := bezierPoint(p0, p1, p2, p3, t)
+		travelled += pos.Sub(prev).Length()
+		prev = pos
+		if travelled < next {
+			continue
+		}
+		next = math.Floor(travelled) + 1
+
+		if !s.visible(l.dashDistance + travelled) {
+			continue
+		}
+		tangent := bezierTangent(p0, p1, p2, p3, t)
+		if tangent.IsZero() {
+			tangent = p3.Sub(p0)
+		}
+		perpDir := tangent.Normalize().Perpendicular()
+		for _, offset := range s.offsets {
+			finalPos := pos.Add(perpDir.Scale(offset))
+			l.drawNeighborsDot(img, finalPos.X, finalPos.Y)
+		}
+	}
+	l.dashDistance += travelled
+}
+
+// calculateCurveLabelPoints returns the middle of the curve and a point ahead of it
+// along the tangent, used to place AutoLeft/AutoRight labels
+func (l *Link) calculateCurveLabelPoints(sourcePt, targetPt image.Point) (image.Point, image.Point) {
+	c1, c2 := l.calculateCurveControlPoints(sourcePt, targetPt)
+	p0 := vector.New(float64(sourcePt.X), float64(sourcePt.Y))
+	p3 := vector.New(float64(targetPt.X), float64(targetPt.Y))
+
+	mid := bezierPoint(p0, c1, c2, p3, 0.5)
+	tangent := bezierTangent(p0, c1, c2, p3, 0.5)
+	if tangent.IsZero() {
+		return sourcePt, targetPt
+	}
+	ahead := mid.Add(tangent.Normalize().Scale(20))
+	return toPoint(mid), toPoint(ahead)
+}
+
+// curvePolyline approximates the curve with straight segments
+func (l *Link) curvePolyline(sourcePt, targetPt image.Point) []image.Point {
+	c1, c2 := l.calculateCurveControlPoints(sourcePt, targetPt)
+	p0 := vector.New(float64(sourcePt.X), float64(sourcePt.Y))
+	p3 := vector.New(float64(targetPt.X), float64(targetPt.Y))
+
+	const steps = 16
+	pts := make([]image.Point, 0, steps+1)
+	for i := 0; i <= steps; i++ {
+		pts = append(pts, toPoint(bezierPoint(p0, c1, c2, p3, float64(i)/steps)))
+	}
+	return pts
+}
+
+// toPoint rounds a vector to the nearest image point
+func toPoint(v vector.Vector) image.Point {
+	return image.Point{int(math.Round(v.X)), int(math.Round(v.Y))}
+}
@@ -0,0 +1,51 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package types
+
+import "image/color"
+
+// Background of the Canvas in dark mode
+var DarkBackgroundColor = color.RGBA{35, 47, 62, 255}
+
+// Default color of titles and link labels, and background of along-path labels.
+// They are switched by SetDarkMode.
+var defaultTextColor = color.RGBA{0, 0, 0, 255}
+var defaultLabelFillColor = color.RGBA{255, 255, 255, 255}
+
+// SetDarkMode sets the default colors for a dark background (true) or a light background (false)
+func SetDarkMode(dark bool) {
+	if dark {
+		defaultTextColor = color.RGBA{230, 230, 230, 255}
+		defaultLabelFillColor = DarkBackgroundColor
+		return
+	}
+	defaultTextColor = color.RGBA{0, 0, 0, 255}
+	defaultLabelFillColor = color.RGBA{255, 255, 255, 255}
+}
+
+// DarkFillColor returns the color with its HSL lightness inverted, keeping its hue, saturation
+// and alpha, so that light fills become dark fills of the same hue
+func DarkFillColor(c color.RGBA) color.RGBA {
+	// Inverting the lightness shifts every channel by 1 - max - min
+	return shiftLightness(c, 255-int(max(c.R, c.G, c.B))-int(min(c.R, c.G, c.B)))
+}
+
+// DarkStrokeColor returns the color with its HSL lightness inverted when it is below one half,
+// so that dark borders, lines and text stay visible on a dark background
+func DarkStrokeColor(c color.RGBA) color.RGBA {
+	shift := 255 - int(max(c.R, c.G, c.B)) - int(min(c.R, c.G, c.B))
+	if shift <= 0 {
+		return c
+	}
+	return shiftLightness(c, shift)
+}
+
+func shiftLightness(c color.RGBA, shift int) color.RGBA {
+	return color.RGBA{
+		uint8(int(c.R) + shift),
+		uint8(int(c.G) + shift),
+		uint8(int(c.B) + shift),
+		c.A,
+	}
+}
@@ -0,0 +1,298 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package types
+
+import (
+	"errors"
+	"fmt"
+	"image"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+
+	fontPath "github.com/awslabs/diagram-as-code/internal/font"
+	"github.com/golang/freetype/truetype"
+	log "github.com/sirupsen/logrus"
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/gofont/gobold"
+	"golang.org/x/image/font/gofont/goregular"
+	"golang.org/x/image/font/opentype"
+	"golang.org/x/image/font/sfnt"
+	"golang.org/x/image/math/fixed"
+)
+
+// Fonts configured for the diagram being created: the directories searched for fonts given by
+// name and the fonts found in them, the fallback fonts given by the user, and the fallback chains
+// and font files resolved from them
+var (
+	fontDirs       []string
+	fontDirFonts   []string
+	fontFallbacks  []string
+	fallbackChains = map[string][]string{}
+	resolvedFonts  = map[string]string{}
+	parsedFonts    = map[string]*parsedFont{}
+	missingGlyphs  = map[rune]bool{}
+	parsedFontsMux sync.Mutex
+)
+
+// SetFontDirs sets the directories searched for fonts given by file name.
+// The directories are searched once here.
+func SetFontDirs(dirs []string) {
+	fontDirs = dirs
+	fontDirFonts = fontPath.Discover(dirs)
+	resolveFallbackChains()
+}
+
+// SetFontFallbacks sets the fonts drawing the characters missing from the title fonts, in order.
+// They are tried before the fallback fonts of the system.
+func SetFontFallbacks(fonts []string) {
+	fontFallbacks = fonts
+	resolveFallbackChains()
+}
+
+// findFont returns the path of a font given by path or by file name in the font directories
+func findFont(name string) string {
+	parsedFontsMux.Lock()
+	defer parsedFontsMux.Unlock()
+	path, ok := resolvedFonts[name]
+	if !ok {
+		path = fontPath.Find(name, fontDirs, fontDirFonts)
+		resolvedFonts[name] = path
+	}
+	return path
+}
+
+// resolveFallbackChains resolves the fallback chains of both weights: the fallback fonts,
+// the fallback fonts of the system and the Go font. Bold variants are used when they are found.
+func resolveFallbackChains() {
+	parsedFontsMux.Lock()
+	resolvedFonts = map[string]string{}
+	parsedFontsMux.Unlock()
+
+	for _, weight := range []string{FONT_WEIGHT_NORMAL, FONT_WEIGHT_BOLD} {
+		chain := []string{}
+		for _, f := range fontFallbacks {
+			chain = append(chain, findFont(f))
+		}
+		chain = append(chain, fontPath.FallbackPaths...)
+
+		goFont := "goregular"
+		if weight == FONT_WEIGHT_BOLD {
+			goFont = "gobold"
+			for i, f := range chain {
+				if bold := boldFontFile(f); bold != "" {
+					chain[i] = bold
+				}
+			}
+		}
+		chain = append(chain, goFont)
+
+		result := []string{}
+		seen := map[string]bool{}
+		for _, f := range chain {
+			if !seen[f] {
+				seen[f] = true
+				result = append(result, f)
+			}
+		}
+		fallbackChains[weight] = result
+	}
+}
+
+// fallbackChain returns the fonts tried in order for characters missing from the font
+func fallbackChain(fontFile string, weight string) []string {
+	chain := []string{}
+	for _, f := range fallbackChains[weight] {
+		if f != fontFile {
+			chain = append(chain, f)
+		}
+	}
+	return chain
+}
+
+// parsedFont is a font file parsed once. TrueType files are drawn with freetype, and OpenType
+// fonts (.otf) and collections (.ttc) with the opentype package.
+type parsedFont struct {
+	tt *truetype.Font
+	sf *sfnt.Font
+}
+
+// splitFontIndex splits the index of a font in a collection from its path (fonts.ttc#1)
+func splitFontIndex(name string) (string, int) {
+	i := strings.LastIndex(name, "#")
+	if i <= 0 {
+		return name, 0
+	}
+	index, err := strconv.Atoi(name[i+1:])
+	if err != nil || index < 0 {
+		return name, 0
+	}
+	if _, err := os.Stat(name); err == nil {
+		return name, 0
+	}
+	return name[:i], index
+}
+
+// parseFont parses the font file, or goregular/gobold for the Go fonts.
+// Collections use the font at the index following # in the name, or the first one.
+func parseFont(name string) (*parsedFont, error) {
+	parsedFontsMux.Lock()
+	defer parsedFontsMux.Unlock()
+	if f, ok := parsedFonts[name]; ok {
+		return f, nil
+	}
+
+	var data []byte
+	index := 0
+	switch name {
+	case "goregular":
+		data = goregular.TTF
+	case "gobold":
+		data = gobold.TTF
+	default:
+		var path string
+		path, index = splitFontIndex(name)
+		var err error
+		data, err = os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read font file: %w", err)
+		}
+	}
+
+	f := &parsedFont{}
+	if tt, err := truetype.Parse(data); err == nil && index == 0 {
+		f.tt = tt
+	} else {
+		collection, err := opentype.ParseCollection(data)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse font %s: %w", name, err)
+		}
+		if index >= collection.NumFonts() {
+			return nil, fmt.Errorf("font %s has %d fonts, index %d is out of range", name, collection.NumFonts(), index)
+		}
+		f.sf, err = collection.Font(index)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse font %s: %w", name, err)
+		}
+	}
+	parsedFonts[name] = f
+	return f, nil
+}
+
+// newFace returns a face of the font at the given size
+func (f *parsedFont) newFace(size float64) (font.Face, error) {
+	if f.tt != nil {
+		return truetype.NewFace(f.tt, &truetype.Options{Size: size}), nil
+	}
+	face, err := opentype.NewFace(f.sf, &opentype.FaceOptions{Size: size, DPI: 72, Hinting: font.HintingNone})
+	if err != nil {
+		return nil, fmt.Errorf("failed to prepare font face: %w", err)
+	}
+	return face, nil
+}
+
+// hasGlyph reports whether the font draws the character
+func (f *parsedFont) hasGlyph(r rune) bool {
+	if f.tt != nil {
+		return f.tt.Index(r) != 0
+	}
+	index, err := f.sf.GlyphIndex(nil, r)
+	return err == nil && index != 0
+}
+
+// fallbackFace draws each character with the first font of the chain that has it.
+// Fallback fonts are loaded when a character is missing from the fonts loaded before them.
+// Metrics are the ones of the first font, so that layout does not depend on the characters.
+type fallbackFace struct {
+	fonts   []*parsedFont
+	faces   []font.Face
+	pending []string // Fonts of the chain not loaded yet
+	size    float64
+	runes   map[rune]int // Index of the face drawing each character
+}
+
+// faceFor returns the face drawing the character
+func (f *fallbackFace) faceFor(r rune) font.Face {
+	i, ok := f.runes[r]
+	if !ok {
+		i = f.findFace(r)
+		f.runes[r] = i
+	}
+	return f.faces[i]
+}
+
+func (f *fallbackFace) findFace(r rune) int {
+	for i, p := range f.fonts {
+		if p.hasGlyph(r) {
+			return i
+		}
+	}
+	if r < ' ' {
+		return 0
+	}
+	for len(f.pending) > 0 {
+		name := f.pending[0]
+		f.pending = f.pending[1:]
+		p, err := parseFont(name)
+		if err != nil {
+			// Fallback fonts of the system are skipped when they are not installed
+			if !errors.Is(err, os.ErrNotExist) {
+				log.Warnf("Skip fallback font %s: %v", name, err)
+			}
+			continue
+		}
+		face, err := p.newFace(f.size)
+		if err != nil {
+			log.Warnf("Skip fallback font %s: %v", name, err)
+			continue
+		}
+		f.fonts = append(f.fonts, p)
+		f.faces = append(f.faces, face)
+		if p.hasGlyph(r) {
+			log.Infof("Draw %q with fallback font %s", r, name)
+			return len(f.faces) - 1
+		}
+	}
+	parsedFontsMux.Lock()
+	if !missingGlyphs[r] {
+		missingGlyphs[r] = true
+		log.Warnf("No font has a glyph for %q (U+%04X). Add a font with --font-fallback.", r, r)
+	}
+	parsedFontsMux.Unlock()
+	return 0
+}
+
+func (f *fallbackFace) Close() error {
+	var errs []error
+	for _, face := range f.faces {
+		errs = append(errs, face.Close())
+	}
+	return errors.Join(errs...)
+}
+
+func (f *fallbackFace) Glyph(dot fixed.Point26_6, r rune) (image.Rectangle, image.Image, image.Point, fixed.Int26_6, bool) {
+	return f.faceFor(r).Glyph(dot, r)
+}
+
+func (f *fallbackFace) GlyphBounds(r rune) (fixed.Rectangle26_6, fixed.Int26_6, bool) {
+	return f.faceFor(r).GlyphBounds(r)
+}
+
+func (f *fallbackFace) GlyphAdvance(r rune) (fixed.Int26_6, bool) {
+	return f.faceFor(r).GlyphAdvance(r)
+}
+
+// Kern returns the kerning of characters drawn with the same font, and 0 otherwise
+func (f *fallbackFace) Kern(r0, r1 rune) fixed.Int26_6 {
+	face := f.faceFor(r0)
+	if face != f.faceFor(r1) {
+		return 0
+	}
+	return face.Kern(r0, r1)
+}
+
+func (f *fallbackFace) Metrics() font.Metrics {
+	return f.faces[0].Metrics()
+}
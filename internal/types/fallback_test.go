@@ -0,0 +1,125 @@
+package types
+
+import (
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	fontPath "github.com/awslabs/diagram-as-code/internal/font"
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/gofont/gobold"
+	"golang.org/x/image/font/gofont/goregular"
+)
+
+// buildCollection returns a TrueType Collection of the fonts, moving their tables after the header
+func buildCollection(fonts ...[]byte) []byte {
+	header := 12 + 4*len(fonts)
+	data := make([]byte, header)
+	copy(data, "ttcf")
+	binary.BigEndian.PutUint32(data[4:], 0x00010000)
+	binary.BigEndian.PutUint32(data[8:], uint32(len(fonts)))
+	for i, f := range fonts {
+		for len(data)%4 != 0 {
+			data = append(data, 0)
+		}
+		base := len(data)
+		binary.BigEndian.PutUint32(data[12+4*i:], uint32(base))
+		f = append([]byte{}, f...)
+		numTables := int(binary.BigEndian.Uint16(f[4:]))
+		for t := 0; t < numTables; t++ {
+			record := 12 + 16*t
+			offset := binary.BigEndian.Uint32(f[record+8:])
+			binary.BigEndian.PutUint32(f[record+8:], offset+uint32(base))
+		}
+		data = append(data, f...)
+	}
+	return data
+}
+
+func TestParseFont_Collection(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "go.ttc")
+	if err := os.WriteFile(path, buildCollection(goregular.TTF, gobold.TTF), 0644); err != nil {
+		t.Fatalf("Failed to write collection: %v", err)
+	}
+
+	regular, err := loadFontFace(path, 24, FONT_WEIGHT_NORMAL)
+	if err != nil {
+		t.Fatalf("Failed to load collection: %v", err)
+	}
+	bold, err := loadFontFace(path+"#1", 24, FONT_WEIGHT_NORMAL)
+	if err != nil {
+		t.Fatalf("Failed to load second font of collection: %v", err)
+	}
+	goFace, _ := loadFontFace("", 24, FONT_WEIGHT_NORMAL)
+	if font.MeasureString(regular, "Amazon S3") != font.MeasureString(goFace, "Amazon S3") {
+		t.Errorf("Expected the first font of the collection to match goregular")
+	}
+	if font.MeasureString(bold, "Amazon S3") <= font.MeasureString(regular, "Amazon S3") {
+		t.Errorf("Expected the second font of the collection to be bold")
+	}
+	if _, err := loadFontFace(path+"#2", 24, FONT_WEIGHT_NORMAL); err == nil {
+		t.Errorf("Expected error for an index out of the collection")
+	}
+}
+
+func TestFallbackChain(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.Mkdir(filepath.Join(dir, "sub"), 0755); err != nil {
+		t.Fatalf("Failed to create directory: %v", err)
+	}
+	for _, name := range []string{"b.ttf", "a.ttc", "notes.txt", "sub/c.otf"} {
+		if err := os.WriteFile(filepath.Join(dir, name), nil, 0644); err != nil {
+			t.Fatalf("Failed to write file: %v", err)
+		}
+	}
+	SetFontDirs([]string{dir})
+	SetFontFallbacks([]string{"first.ttf", "b"})
+	defer SetFontDirs(nil)
+	defer SetFontFallbacks(nil)
+
+	// Fonts of the directories are found by name but are not fallback fonts
+	expected := []string{"first.ttf", filepath.Join(dir, "b.ttf")}
+	expected = append(expected, fontPath.FallbackPaths...)
+	expected = append(expected, "goregular")
+	if chain := fallbackChain("primary.ttf", FONT_WEIGHT_NORMAL); !reflect.DeepEqual(chain, expected) {
+		t.Errorf("Expected chain %v, got %v", expected, chain)
+	}
+	if chain := fallbackChain("goregular", FONT_WEIGHT_NORMAL); chain[len(chain)-1] == "goregular" {
+		t.Errorf("Expected the primary font not to be repeated in the chain, got %v", chain)
+	}
+	if path := findFont("c"); path != filepath.Join(dir, "sub", "c.otf") {
+		t.Errorf("Expected the font of a subdirectory to be found by name, got %s", path)
+	}
+}
+
+func TestFallbackFace(t *testing.T) {
+	// The chain only has Go fonts, so that the fonts installed on the host do not matter
+	regular, err := parseFont("goregular")
+	if err != nil {
+		t.Fatalf("Failed to parse font: %v", err)
+	}
+	face, err := regular.newFace(24)
+	if err != nil {
+		t.Fatalf("Failed to prepare font face: %v", err)
+	}
+	f := &fallbackFace{
+		fonts:   []*parsedFont{regular},
+		faces:   []font.Face{face},
+		pending: []string{"gobold"},
+		size:    24,
+		runes:   map[rune]int{},
+	}
+	f.faceFor('A')
+	if len(f.faces) != 1 {
+		t.Errorf("Expected fallback fonts not to be loaded for characters of the font, got %d faces", len(f.faces))
+	}
+	// No Go font has CJK characters, so the first font draws them
+	if f.faceFor('日') != f.faces[0] {
+		t.Errorf("Expected the first font for a character missing from every font")
+	}
+	if len(f.faces) != 2 || len(f.pending) != 0 {
+		t.Errorf("Expected every font of the chain to be tried, got %d faces and %d pending", len(f.faces), len(f.pending))
+	}
+}
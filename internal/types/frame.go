@@ -0,0 +1,109 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package types
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"math"
+)
+
+const (
+	shadowOffset = 4.0 // Offset of the shadow downwards and to the right
+	shadowBlur   = 6.0 // Distance over which the shadow fades out
+	shadowAlpha  = 70.0
+)
+
+func (r *Resource) SetBorderWidth(width int) error {
+	if width < 0 {
+		return fmt.Errorf("border width must not be negative, got %d", width)
+	}
+	r.borderWidth = width
+	return nil
+}
+
+func (r *Resource) SetCornerRadius(radius int) error {
+	if radius < 0 {
+		return fmt.Errorf("corner radius must not be negative, got %d", radius)
+	}
+	r.cornerRadius = radius
+	return nil
+}
+
+func (r *Resource) SetShadow(shadow bool) {
+	r.shadow = shadow
+}
+
+// hasStyledFrame reports whether the frame is drawn with antialiasing: with a border width,
+// rounded corners or a shadow. Other frames keep the 2px border of drawFrame.
+func (r *Resource) hasStyledFrame() bool {
+	return r.borderWidth > 0 || r.cornerRadius > 0 || r.shadow
+}
+
+// roundedRectDistance returns the signed distance from p to the border of rect with rounded corners:
+// negative inside, positive outside
+func roundedRectDistance(px, py float64, rect image.Rectangle, radius float64) float64 {
+	hx := float64(rect.Dx()) / 2
+	hy := float64(rect.Dy()) / 2
+	radius = math.Min(radius, math.Min(hx, hy))
+	qx := math.Abs(px-float64(rect.Min.X)-hx) - (hx - radius)
+	qy := math.Abs(py-float64(rect.Min.Y)-hy) - (hy - radius)
+	outside := math.Hypot(math.Max(qx, 0), math.Max(qy, 0))
+	inside := math.Min(math.Max(qx, qy), 0)
+	return outside + inside - radius
+}
+
+// drawStyledFrame draws the shadow, fill and border of the resource with antialiased edges.
+// The border is centered on the bindings like the border of drawFrame.
+func (r *Resource) drawStyledFrame(img *image.RGBA) {
+	rect := *r.bindings
+	width := float64(WIDTH)
+	if r.borderWidth > 0 {
+		width = float64(r.borderWidth)
+	}
+	radius := float64(r.cornerRadius)
+	extent := int(math.Ceil(width/2)) + 1
+	if r.shadow {
+		extent = max(extent, int(shadowOffset+shadowBlur)+1)
+	}
+	area := rect.Inset(-extent).Intersect(img.Bounds())
+	// Dashes scale with the border width
+	period := 9 * math.Max(1, width/2)
+
+	shadowRect := rect.Add(image.Point{int(shadowOffset), int(shadowOffset)})
+	for y := area.Min.Y; y < area.Max.Y; y++ {
+		for x := area.Min.X; x < area.Max.X; x++ {
+			px, py := float64(x)+0.5, float64(y)+0.5
+			d := roundedRectDistance(px, py, rect, radius)
+			outside := math.Min(math.Max(d+0.5, 0), 1)
+
+			// Shadow outside the frame, fading out over shadowBlur
+			if r.shadow && outside > 0 {
+				ds := roundedRectDistance(px, py, shadowRect, radius)
+				a := shadowAlpha * math.Min(math.Max((shadowBlur/2-ds)/shadowBlur, 0), 1) * outside
+				if a > 0 {
+					img.Set(x, y, _blend_color(img.At(x, y), color.RGBA{0, 0, 0, uint8(a)}))
+				}
+			}
+			// Background (skip for overlay resources)
+			if len(r.spanTargets) == 0 && outside < 1 {
+				c := r.fillColor
+				c.A = uint8(float64(c.A) * (1 - outside))
+				img.Set(x, y, _blend_color(img.At(x, y), c))
+			}
+			// Border
+			coverage := math.Min(math.Max(width/2+0.5-math.Abs(d), 0), 1)
+			if coverage == 0 || r.borderColor == nil {
+				continue
+			}
+			if r.borderType == BORDER_TYPE_DASHED && math.Mod(float64(x+y), period) >= period*2/3 {
+				continue
+			}
+			c := *r.borderColor
+			c.A = uint8(float64(c.A) * coverage)
+			img.Set(x, y, _blend_color(img.At(x, y), c))
+		}
+	}
+}
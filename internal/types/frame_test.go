@@ -0,0 +1,81 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package types
+
+import (
+	"image"
+	"image/color"
+	"math"
+	"testing"
+)
+
+func TestRoundedRectDistance(t *testing.T) {
+	rect := image.Rect(0, 0, 100, 50)
+	tests := []struct {
+		name     string
+		x, y     float64
+		radius   float64
+		expected float64
+	}{
+		{"Center", 50, 25, 10, -25},
+		{"LeftEdge", 0, 25, 10, 0},
+		{"OutsideRight", 110, 25, 10, 10},
+		{"SharpCorner", 0, 0, 0, 0},
+		{"RoundedCorner", 0, 0, 10, 10*math.Sqrt2 - 10},
+		{"RadiusClamped", 0, 0, 100, 25*math.Sqrt2 - 25},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if d := roundedRectDistance(tt.x, tt.y, rect, tt.radius); math.Abs(d-tt.expected) > 1e-9 {
+				t.Errorf("Expected %v, got %v", tt.expected, d)
+			}
+		})
+	}
+}
+
+func TestDrawStyledFrame(t *testing.T) {
+	blue := color.RGBA{0, 0, 255, 255}
+	white := color.RGBA{255, 255, 255, 255}
+	r := new(Resource).Init()
+	r.SetBorderColor(blue)
+	r.SetFillColor(white)
+	if err := r.SetBorderWidth(4); err != nil {
+		t.Fatalf("SetBorderWidth failed: %v", err)
+	}
+	if err := r.SetCornerRadius(20); err != nil {
+		t.Fatalf("SetCornerRadius failed: %v", err)
+	}
+	r.SetShadow(true)
+	r.bindings = &image.Rectangle{image.Point{20, 20}, image.Point{120, 100}}
+	if !r.hasStyledFrame() {
+		t.Fatal("Expected a styled frame")
+	}
+	img := image.NewRGBA(image.Rect(0, 0, 140, 120))
+	r.drawFrame(img)
+
+	// The 4px border is centered on the top edge
+	for _, y := range []int{18, 21} {
+		if c := img.RGBAAt(70, y); c != blue {
+			t.Errorf("Expected the border at y=%d, got %v", y, c)
+		}
+	}
+	if c := img.RGBAAt(70, 23); c != white {
+		t.Errorf("Expected the fill inside the border, got %v", c)
+	}
+	if c := img.RGBAAt(70, 60); c != white {
+		t.Errorf("Expected the fill inside the frame, got %v", c)
+	}
+	if c := img.RGBAAt(21, 21); c.A != 0 {
+		t.Errorf("Expected the corner to be rounded, got %v", c)
+	}
+	if c := img.RGBAAt(70, 104); c.A == 0 || c.A == 255 {
+		t.Errorf("Expected a translucent shadow below the frame, got %v", c)
+	}
+	if c := img.RGBAAt(70, 10); c.A != 0 {
+		t.Errorf("Expected no shadow above the frame, got %v", c)
+	}
+	if err := r.SetBorderWidth(-1); err == nil {
+		t.Error("Expected an error for a negative border width")
+	}
+}
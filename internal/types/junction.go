@@ -0,0 +1,172 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package types
+
+import (
+	"fmt"
+	"image"
+	"math"
+
+	"github.com/awslabs/diagram-as-code/internal/vector"
+	log "github.com/sirupsen/logrus"
+)
+
+// Distance kept between a junction and the ends of the target link, so that
+// junctions do not land on the resources the target link connects
+const junctionEndMargin = 10
+
+// SetTargetLink makes the link end on another link instead of a resource.
+// Such junction links are drawn by DrawJunction after every resource link is drawn.
+func (l *Link) SetTargetLink(target *Link) {
+	l.TargetLink = target
+}
+
+// IsJunction reports whether the link ends on another link
+func (l *Link) IsJunction() bool {
+	return l.TargetLink != nil
+}
+
+// nearestPointOnPath returns the point of the polyline nearest to pt, away from both ends of
+// the polyline, and whether the segment it is on is horizontal
+func nearestPointOnPath(path []image.Point, pt image.Point) (vector.Vector, bool) {
+	p := vector.New(float64(pt.X), float64(pt.Y))
+	total := pathLength(path)
+	margin := math.Min(junctionEndMargin, total/2)
+
+	best := vector.Vector{}
+	bestHorizontal := false
+	bestDistance := math.Inf(1)
+	travelled := 0.0
+	for i := 0; i < len(path)-1; i++ {
+		a := vector.New(float64(path[i].X), float64(path[i].Y))
+		d := vector.New(float64(path[i+1].X-path[i].X), float64(path[i+1].Y-path[i].Y))
+		length := d.Length()
+		if length == 0 {
+			continue
+		}
+		// Distance along the segment, clamped to the segment and away from the ends of the path
+		t := p.Sub(a).Dot(d) / length
+		t = math.Max(t, math.Max(0, margin-travelled))
+		t = math.Min(t, math.Min(length, total-margin-travelled))
+		travelled += length
+		if t < 0 || t > length {
+			continue
+		}
+		candidate := a.Add(d.Scale(t / length))
+		if distance := candidate.Sub(p).Length(); distance < bestDistance {
+			best = candidate
+			bestHorizontal = math.Abs(d.X) >= math.Abs(d.Y)
+			bestDistance = distance
+		}
+	}
+	return best, bestHorizontal
+}
+
+// directionToWindrose returns N, E, S or W for the dominant axis of a direction
+func directionToWindrose(d vector.Vector) Windrose {
+	if math.Abs(d.X) >= math.Abs(d.Y) {
+		if d.X >= 0 {
+			return WINDROSE_E
+		}
+		return WINDROSE_W
+	}
+	if d.Y >= 0 {
+		return WINDROSE_S
+	}
+	return WINDROSE_N
+}
+
+// junctionPath returns the path from the source to the junction point on the target link
+func (l *Link) junctionPath() []image.Point {
+	path := mergeCollinearPoints(l.TargetLink.path)
+
+	// Without a source position, leave the source on the side facing the target link
+	if l.SourcePosition == WINDROSE_AUTO || l.SourcePosition == WINDROSE_NEAREST {
+		center := resourceCenter(l.Source)
+		pt, _ := nearestPointOnPath(path, center)
+		l.SourcePosition = directionToWindrose(pt.Sub(vector.New(float64(center.X), float64(center.Y))))
+	}
+	sourcePt := l.calcPositionWithOffset(l.Source.GetBindings(), l.SourcePosition, l.Source, true)
+	junction, horizontal := nearestPointOnPath(path, sourcePt)
+	junctionPt := toPoint(junction)
+
+	if !l.isOrthogonal() || sourcePt.X == junctionPt.X || sourcePt.Y == junctionPt.Y {
+		return []image.Point{sourcePt, junctionPt}
+	}
+	// The last leg joins the target link at a right angle
+	if horizontal {
+		return []image.Point{sourcePt, {junctionPt.X, sourcePt.Y}, junctionPt}
+	}
+	return []image.Point{sourcePt, {sourcePt.X, junctionPt.Y}, junctionPt}
+}
+
+// DrawJunction draws a link ending on another link, with a dot at the junction.
+// The target link is drawn first when it is itself a junction link.
+func (l *Link) DrawJunction(img *image.RGBA) error {
+	if l.drawn {
+		return nil
+	}
+	if l.TargetLink.IsJunction() {
+		if err := l.TargetLink.DrawJunction(img); err != nil {
+			return err
+		}
+	}
+	if len(l.TargetLink.path) < 2 {
+		log.Warnf("Skip junction link: the target link is not drawn")
+		return nil
+	}
+	log.Info("Junction Link Drawing")
+	l.dashDistance = 0
+
+	path := l.junctionPath()
+	n := len(path)
+	sourcePt, junctionPt := path[0], path[n-1]
+	l.drawPath(img, path)
+	l.drawArrowHead(img, sourcePt, path[1], l.SourceArrowHead)
+	l.drawArrowHead(img, junctionPt, path[n-2], l.TargetArrowHead)
+	l.drawJunctionDot(img, junctionPt)
+
+	// The junction end of the link faces the segment before it
+	last := vector.New(float64(path[n-2].X-junctionPt.X), float64(path[n-2].Y-junctionPt.Y))
+	junctionPos := directionToWindrose(last)
+	labels := []struct {
+		label    *LinkLabel
+		pos      Windrose
+		from, to image.Point
+		side     string
+	}{
+		{l.Labels.SourceRight, l.SourcePosition, sourcePt, path[1], "Right"},
+		{l.Labels.SourceLeft, l.SourcePosition, sourcePt, path[1], "Left"},
+		{l.Labels.TargetRight, junctionPos, junctionPt, path[n-2], "Left"},
+		{l.Labels.TargetLeft, junctionPos, junctionPt, path[n-2], "Right"},
+		{l.Labels.AutoRight, directionToWindrose(last.Scale(-1)), path[n-2], junctionPt, "Right"},
+		{l.Labels.AutoLeft, directionToWindrose(last.Scale(-1)), path[n-2], junctionPt, "Left"},
+	}
+	for _, v := range labels {
+		if err := l.drawLabel(img, v.pos, l.Source, nil, v.from, v.to, v.side, v.label); err != nil {
+			return fmt.Errorf("failed to draw junction link label: %w", err)
+		}
+	}
+	source, target, auto := pathLabelAnchors(path)
+	if err := l.drawAlongPathLabels(img, source, target, auto); err != nil {
+		return err
+	}
+
+	l.recordLinkSegments(mergeCollinearPoints(path))
+	l.path = path
+	l.drawn = true
+	return nil
+}
+
+// drawJunctionDot draws a filled dot at the junction, made of antialiased dots
+func (l *Link) drawJunctionDot(img *image.RGBA, pt image.Point) {
+	radius := float64(l.LineWidth + 2)
+	for dy := -radius; dy <= radius; dy += 0.5 {
+		for dx := -radius; dx <= radius; dx += 0.5 {
+			if dx*dx+dy*dy <= radius*radius {
+				l.drawNeighborsDot(img, float64(pt.X)+dx, float64(pt.Y)+dy)
+			}
+		}
+	}
+}
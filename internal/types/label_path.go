@@ -0,0 +1,196 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package types
+
+import (
+	"fmt"
+	"image"
+	"math"
+	"strings"
+
+	"github.com/awslabs/diagram-as-code/internal/vector"
+	"golang.org/x/image/font"
+	"golang.org/x/image/math/fixed"
+)
+
+const (
+	alongPathLabelPadding = 3  // Space between the text and the edge of its background
+	alongPathLabelGap     = 10 // Distance between a source/target label and the resource
+)
+
+// labelAnchor is a point on the link path and the direction of travel (source to target) there
+type labelAnchor struct {
+	point     vector.Vector
+	direction vector.Vector
+}
+
+// pathLabelAnchors returns the anchors for along-path labels of a polyline path:
+// both ends of the path, and the middle of its longest segment.
+func pathLabelAnchors(path []image.Point) (source, target, auto labelAnchor) {
+	toVec := func(p image.Point) vector.Vector {
+		return vector.New(float64(p.X), float64(p.Y))
+	}
+	n := len(path)
+	source = labelAnchor{toVec(path[0]), toVec(path[1]).Sub(toVec(path[0]))}
+	target = labelAnchor{toVec(path[n-1]), toVec(path[n-1]).Sub(toVec(path[n-2]))}
+
+	longest := 0.0
+	for i := 0; i < n-1; i++ {
+		d := toVec(path[i+1]).Sub(toVec(path[i]))
+		if d.Length() > longest {
+			longest = d.Length()
+			auto = labelAnchor{toVec(path[i]).Add(d.Scale(0.5)), d}
+		}
+	}
+	return source, target, auto
+}
+
+// curveLabelAnchors returns the anchors for along-path labels of a curved link
+func (l *Link) curveLabelAnchors(sourcePt, targetPt image.Point) (source, target, auto labelAnchor) {
+	c1, c2 := l.calculateCurveControlPoints(sourcePt, targetPt)
+	p0 := vector.New(float64(sourcePt.X), float64(sourcePt.Y))
+	p3 := vector.New(float64(targetPt.X), float64(targetPt.Y))
+	source = labelAnchor{p0, c1.Sub(p0)}
+	target = labelAnchor{p3, p3.Sub(c2)}
+	auto = labelAnchor{bezierPoint(p0, c1, c2, p3, 0.5), bezierTangent(p0, c1, c2, p3, 0.5)}
+	return source, target, auto
+}
+
+// drawAlongPathLabels draws every label of type along-path.
+// Auto labels are centered on the anchor, source labels start after the source and
+// target labels end before the target. Right/Left is relative to the direction of travel.
+func (l *Link) drawAlongPathLabels(img *image.RGBA, source, target, auto labelAnchor) error {
+	labels := []struct {
+		label  *LinkLabel
+		anchor labelAnchor
+		side   string
+		align  float64 // -1: end at the anchor, 0: centered, 1: start at the anchor
+	}{
+		{l.Labels.SourceRight, source, "Right", 1},
+		{l.Labels.SourceLeft, source, "Left", 1},
+		{l.Labels.TargetRight, target, "Right", -1},
+		{l.Labels.TargetLeft, target, "Left", -1},
+		{l.Labels.AutoRight, auto, "Right", 0},
+		{l.Labels.AutoLeft, auto, "Left", 0},
+	}
+	for _, v := range labels {
+		if v.label == nil || v.label.Type != LINK_LABEL_TYPE_ALONG_PATH {
+			continue
+		}
+		if err := l.drawAlongPathLabel(img, v.label, v.anchor, v.side, v.align); err != nil {
+			return fmt.Errorf("failed to draw %s along-path label: %w", strings.ToLower(v.side), err)
+		}
+	}
+	return nil
+}
+
+// drawAlongPathLabel draws a label rotated to the direction of the anchor, with a background fill.
+// The text is kept upright, so labels on right-to-left segments are turned by 180 degrees.
+func (l *Link) drawAlongPathLabel(img *image.RGBA, label *LinkLabel, anchor labelAnchor, side string, align float64) error {
+	if anchor.direction.IsZero() {
+		return nil
+	}
+	face, err := l.prepareFontFace(label, l.Source, l.Target)
+	if err != nil {
+		return fmt.Errorf("failed to prepare font face for link label: %w", err)
+	}
+
+	// Render the text horizontally into a coverage mask
+	lines := wrapText(face, label.Title, label.MaxWidth)
+	metrics := face.Metrics()
+	lineHeight := (metrics.Ascent + metrics.Descent).Ceil() + label.LineSpacing
+	textWidth := 0
+	for _, line := range lines {
+		textWidth = max(textWidth, font.MeasureString(face, line).Ceil())
+	}
+	w := textWidth + alongPathLabelPadding*2
+	h := lineHeight*len(lines) - label.LineSpacing + alongPathLabelPadding*2
+	mask := image.NewAlpha(image.Rect(0, 0, w, h))
+	for i, line := range lines {
+		d := &font.Drawer{
+			Dst:  mask,
+			Src:  image.Opaque,
+			Face: face,
+			Dot: fixed.Point26_6{
+				X: fixed.I(alongPathLabelPadding + (textWidth-font.MeasureString(face, line).Ceil())/2),
+				Y: fixed.I(alongPathLabelPadding+lineHeight*i) + metrics.Ascent,
+			},
+		}
+		d.DrawString(line)
+	}
+
+	// Place the label box along the path and on the requested side of it
+	dir := anchor.direction.Normalize()
+	normal := vector.New(-dir.Y, dir.X) // Right-hand side of the direction of travel
+	if side == "Left" {
+		normal = normal.Scale(-1)
+	}
+	center := anchor.point.
+		Add(dir.Scale(align * (alongPathLabelGap + float64(w)/2))).
+		Add(normal.Scale(float64(h)/2 + float64(l.LineWidth)/2 + 2))
+
+	// Keep the text upright
+	if dir.X < -1e-9 || (math.Abs(dir.X) <= 1e-9 && dir.Y > 0) {
+		dir = dir.Scale(-1)
+	}
+	up := vector.New(-dir.Y, dir.X).Scale(-1)
+
+	fill := defaultLabelFillColor
+	if label.FillColor != nil {
+		fill = *label.FillColor
+	}
+	textColor := defaultTextColor
+	if label.Color != nil {
+		textColor = *label.Color
+	}
+
+	// Inverse-map every pixel of the rotated box to the mask
+	halfDiag := math.Hypot(float64(w), float64(h)) / 2
+	bounds := image.Rect(
+		int(math.Floor(center.X-halfDiag)), int(math.Floor(center.Y-halfDiag)),
+		int(math.Ceil(center.X+halfDiag)), int(math.Ceil(center.Y+halfDiag)),
+	).Intersect(img.Bounds())
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			p := vector.New(float64(x)+0.5, float64(y)+0.5).Sub(center)
+			u := p.Dot(dir) + float64(w)/2
+			v := -p.Dot(up) + float64(h)/2
+
+			// Coverage of the background box, antialiased at its edges
+			coverage := math.Min(math.Min(u+0.5, float64(w)-u+0.5), math.Min(v+0.5, float64(h)-v+0.5))
+			if coverage <= 0 {
+				continue
+			}
+			coverage = math.Min(coverage, 1)
+			if fill.A > 0 {
+				c := fill
+				c.A = uint8(float64(fill.A) * coverage)
+				img.Set(x, y, _blend_color(img.At(x, y), c))
+			}
+			if a := sampleAlpha(mask, u-0.5, v-0.5); a > 0 {
+				c := textColor
+				c.A = uint8(float64(textColor.A) * a * coverage)
+				img.Set(x, y, _blend_color(img.At(x, y), c))
+			}
+		}
+	}
+	return nil
+}
+
+// sampleAlpha returns the bilinearly interpolated coverage of the mask at (x, y) in [0, 1]
+func sampleAlpha(mask *image.Alpha, x, y float64) float64 {
+	x0 := int(math.Floor(x))
+	y0 := int(math.Floor(y))
+	fx := x - float64(x0)
+	fy := y - float64(y0)
+	at := func(px, py int) float64 {
+		if !(image.Point{px, py}.In(mask.Rect)) {
+			return 0
+		}
+		return float64(mask.AlphaAt(px, py).A) / 255
+	}
+	top := at(x0, y0)*(1-fx) + at(x0+1, y0)*fx
+	bottom := at(x0, y0+1)*(1-fx) + at(x0+1, y0+1)*fx
+	return top*(1-fy) + bottom*fy
+}
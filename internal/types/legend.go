@@ -0,0 +1,208 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package types
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+
+	"golang.org/x/image/draw"
+	"golang.org/x/image/font"
+	"golang.org/x/image/math/fixed"
+)
+
+const (
+	legendTitleFontSize = 20.0
+	legendFontSize      = 18.0
+	legendPadding       = 12 // Space between the legend frame and its entries
+	legendMargin        = 20 // Space around the legend
+	legendSampleWidth   = 56
+	legendSampleHeight  = 32
+	legendGap           = 12 // Space between a sample and its description, and between entries
+)
+
+var legendBorderColor = color.RGBA{128, 128, 128, 255}
+
+// LegendEntry is a line of the legend: a sample and its description.
+// The sample is a link style, or the border, fill and icon of a resource.
+type LegendEntry struct {
+	Description string
+	Link        *Link
+	Resource    *Resource
+}
+
+// Legend is a box listing samples of the styles used in the diagram, drawn outside the diagram
+// on the side given by Position (N, NE, NW, S, SE, SW, E or W)
+type Legend struct {
+	Title    string
+	Position Windrose
+	Entries  []LegendEntry
+}
+
+// extendImage returns a copy of img extended to include area, moved so that it starts at (0, 0),
+// and the offset by which the points of img are moved. The extended area is filled with background,
+// which is the fill color of the Canvas.
+func extendImage(img *image.RGBA, area image.Rectangle, background color.RGBA) (*image.RGBA, image.Point) {
+	bounds := img.Bounds()
+	union := bounds.Union(area)
+	offset := image.Point{}.Sub(union.Min)
+	result := image.NewRGBA(image.Rect(0, 0, union.Dx(), union.Dy()))
+	draw.Draw(result, result.Bounds(), image.NewUniform(background), image.Point{}, draw.Src)
+	draw.Draw(result, bounds.Add(offset), img, bounds.Min, draw.Over)
+	return result, offset
+}
+
+// textColorOn returns a text color readable on the background: light on dark backgrounds,
+// black otherwise
+func textColorOn(background color.RGBA) color.RGBA {
+	if background.A > 0 && 0.299*float64(background.R)+0.587*float64(background.G)+0.114*float64(background.B) < 128 {
+		return color.RGBA{230, 230, 230, 255}
+	}
+	return color.RGBA{0, 0, 0, 255}
+}
+
+// drawBoxBorder draws a 1px border along the inside of frame
+func drawBoxBorder(img *image.RGBA, frame image.Rectangle, c color.RGBA) {
+	for x := frame.Min.X; x < frame.Max.X; x++ {
+		img.SetRGBA(x, frame.Min.Y, c)
+		img.SetRGBA(x, frame.Max.Y-1, c)
+	}
+	for y := frame.Min.Y; y < frame.Max.Y; y++ {
+		img.SetRGBA(frame.Min.X, y, c)
+		img.SetRGBA(frame.Max.X-1, y, c)
+	}
+}
+
+// legendFrame returns the frame of a legend of the given size on the side of bounds given by position.
+// Frames above or below the diagram are aligned to its left, center or right.
+func legendFrame(bounds image.Rectangle, size image.Point, position Windrose) (image.Rectangle, error) {
+	x := bounds.Min.X + (bounds.Dx()-size.X)/2
+	switch position {
+	case WINDROSE_NW, WINDROSE_SW:
+		x = bounds.Min.X + legendMargin
+	case WINDROSE_NE, WINDROSE_SE:
+		x = max(bounds.Max.X-legendMargin-size.X, bounds.Min.X+legendMargin)
+	}
+	y := bounds.Min.Y + max((bounds.Dy()-size.Y)/2, legendMargin)
+
+	var pt image.Point
+	switch position {
+	case WINDROSE_S, WINDROSE_SE, WINDROSE_SW:
+		pt = image.Point{x, bounds.Max.Y}
+	case WINDROSE_N, WINDROSE_NE, WINDROSE_NW:
+		pt = image.Point{x, bounds.Min.Y - size.Y}
+	case WINDROSE_E:
+		pt = image.Point{bounds.Max.X, y}
+	case WINDROSE_W:
+		pt = image.Point{bounds.Min.X - size.X, y}
+	default:
+		return image.Rectangle{}, fmt.Errorf("unsupported legend position (allowed: N, NE, NW, S, SE, SW, E, W)")
+	}
+	return image.Rectangle{pt, pt.Add(size)}, nil
+}
+
+// Draw returns a copy of img extended with the legend, drawn on the background color with
+// the font file of the diagram. It returns img when the legend has no entries.
+func (lg *Legend) Draw(img *image.RGBA, background color.RGBA, fontFile string) (*image.RGBA, error) {
+	if len(lg.Entries) == 0 {
+		return img, nil
+	}
+	titleFace, err := loadFontFace(fontFile, legendTitleFontSize, FONT_WEIGHT_BOLD)
+	if err != nil {
+		return nil, fmt.Errorf("failed to prepare font face for legend: %w", err)
+	}
+	textFace, err := loadFontFace(fontFile, legendFontSize, FONT_WEIGHT_NORMAL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to prepare font face for legend: %w", err)
+	}
+
+	titleHeight := 0
+	width := 0
+	if lg.Title != "" {
+		metrics := titleFace.Metrics()
+		titleHeight = (metrics.Ascent + metrics.Descent).Ceil() + legendGap
+		width = font.MeasureString(titleFace, lg.Title).Ceil()
+	}
+	for _, entry := range lg.Entries {
+		width = max(width, legendSampleWidth+legendGap+font.MeasureString(textFace, entry.Description).Ceil())
+	}
+	size := image.Point{
+		legendPadding*2 + width,
+		legendPadding*2 + titleHeight + (legendSampleHeight+legendGap)*len(lg.Entries) - legendGap,
+	}
+	frame, err := legendFrame(img.Bounds(), size, lg.Position)
+	if err != nil {
+		return nil, err
+	}
+	result, offset := extendImage(img, frame.Inset(-legendMargin), background)
+	frame = frame.Add(offset)
+
+	drawBoxBorder(result, frame, legendBorderColor)
+
+	textColor := image.NewUniform(textColorOn(background))
+	x := frame.Min.X + legendPadding
+	y := frame.Min.Y + legendPadding
+	if lg.Title != "" {
+		d := &font.Drawer{Dst: result, Src: textColor, Face: titleFace}
+		d.Dot = fixed.Point26_6{X: fixed.I(x), Y: fixed.I(y) + titleFace.Metrics().Ascent}
+		d.DrawString(lg.Title)
+		y += titleHeight
+	}
+	metrics := textFace.Metrics()
+	for _, entry := range lg.Entries {
+		sample := image.Rect(x, y, x+legendSampleWidth, y+legendSampleHeight)
+		if entry.Link != nil {
+			entry.Link.drawLegendSample(result, sample)
+		} else if entry.Resource != nil {
+			entry.Resource.drawLegendSample(result, sample)
+		}
+		d := &font.Drawer{Dst: result, Src: textColor, Face: textFace}
+		d.Dot = fixed.Point26_6{
+			X: fixed.I(sample.Max.X + legendGap),
+			Y: fixed.I(y+legendSampleHeight/2) + (metrics.Ascent-metrics.Descent)/2,
+		}
+		d.DrawString(entry.Description)
+		y += legendSampleHeight + legendGap
+	}
+	return result, nil
+}
+
+// drawLegendSample draws a short horizontal line of the link style with its arrow heads
+func (l *Link) drawLegendSample(img *image.RGBA, rect image.Rectangle) {
+	cy := (rect.Min.Y + rect.Max.Y) / 2
+	a := image.Point{rect.Min.X + 2, cy}
+	b := image.Point{rect.Max.X - 2, cy}
+	l.dashDistance = 0
+	l.drawLine(img, a, b)
+	l.drawArrowHead(img, a, b, l.SourceArrowHead)
+	l.drawArrowHead(img, b, a, l.TargetArrowHead)
+}
+
+// drawLegendSample draws the border and fill of the resource as a small box, with its icon
+// in the top-left corner, or only the icon when the resource has neither border nor fill
+func (r *Resource) drawLegendSample(img *image.RGBA, rect image.Rectangle) {
+	s := *r
+	s.bindings = &rect
+	s.spanTargets = nil
+	if s.borderColor == nil {
+		s.borderColor = &color.RGBA{0, 0, 0, 0}
+	}
+	hasIcon := r.iconImage.Bounds().Dx() > 0
+	hasFrame := s.borderColor.A > 0 || s.fillColor.A > 0
+	if hasFrame {
+		s.drawFrame(img)
+	}
+	if !hasIcon {
+		return
+	}
+	iconSize := rect.Dy()
+	origin := image.Point{rect.Min.X + (rect.Dx()-iconSize)/2, rect.Min.Y}
+	if hasFrame {
+		iconSize = rect.Dy() * 3 / 4
+		origin = rect.Min
+	}
+	icon := image.Rectangle{origin, origin.Add(image.Point{iconSize, iconSize})}
+	draw.CatmullRom.Scale(img, icon, r.iconImage, r.iconImage.Bounds(), draw.Over, nil)
+}
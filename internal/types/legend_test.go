@@ -0,0 +1,126 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package types
+
+import (
+	"image"
+	"image/color"
+	"testing"
+)
+
+func TestLegendFrame(t *testing.T) {
+	bounds := image.Rect(0, 0, 400, 300)
+	size := image.Point{100, 50}
+	tests := []struct {
+		position Windrose
+		expected image.Point
+	}{
+		{WINDROSE_SE, image.Point{280, 300}},
+		{WINDROSE_SW, image.Point{20, 300}},
+		{WINDROSE_S, image.Point{150, 300}},
+		{WINDROSE_N, image.Point{150, -50}},
+		{WINDROSE_NE, image.Point{280, -50}},
+		{WINDROSE_E, image.Point{400, 125}},
+		{WINDROSE_W, image.Point{-100, 125}},
+	}
+	for _, tt := range tests {
+		frame, err := legendFrame(bounds, size, tt.position)
+		if err != nil {
+			t.Errorf("Position %d: unexpected error: %v", tt.position, err)
+			continue
+		}
+		if frame.Min != tt.expected || frame.Size() != size {
+			t.Errorf("Position %d: expected frame at %v, got %v", tt.position, tt.expected, frame)
+		}
+	}
+	if _, err := legendFrame(bounds, size, WINDROSE_NNE); err == nil {
+		t.Error("Expected an error for a position between the main directions")
+	}
+}
+
+func TestLegendDraw(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 200, 100))
+	white := color.RGBA{255, 255, 255, 255}
+	for i := range img.Pix {
+		img.Pix[i] = 255
+	}
+	red := color.RGBA{255, 0, 0, 255}
+	border := new(Resource).Init()
+	border.SetBorderColor(red)
+	legend := &Legend{
+		Title:    "Legend",
+		Position: WINDROSE_S,
+		Entries: []LegendEntry{
+			{Description: "Replication", Link: new(Link).Init(nil, WINDROSE_AUTO, ArrowHead{}, nil, WINDROSE_AUTO, ArrowHead{Type: "Open"}, 2, red)},
+			{Description: "Private subnet", Resource: border},
+		},
+	}
+	result, err := legend.Draw(img, white, "")
+	if err != nil {
+		t.Fatalf("Draw failed: %v", err)
+	}
+	b := result.Bounds()
+	if b.Dx() < 200 || b.Dy() <= 100 {
+		t.Fatalf("Expected the image to be extended below the diagram, got %v", b)
+	}
+	if c := result.RGBAAt(b.Max.X-1, b.Max.Y-1); c != white {
+		t.Errorf("Expected the extended area to take the background color, got %v", c)
+	}
+	found := false
+	for y := 100; y < b.Max.Y && !found; y++ {
+		for x := 0; x < b.Max.X; x++ {
+			if result.RGBAAt(x, y) == red {
+				found = true
+				break
+			}
+		}
+	}
+	if !found {
+		t.Error("Expected the samples to be drawn in the legend")
+	}
+
+	// The extended area takes the canvas background, and the text stays readable on it
+	dark := color.RGBA{35, 47, 62, 255}
+	transparent := image.NewRGBA(image.Rect(0, 0, 200, 100))
+	result, err = legend.Draw(transparent, dark, "")
+	if err != nil {
+		t.Fatalf("Draw failed: %v", err)
+	}
+	b = result.Bounds()
+	if c := result.RGBAAt(b.Max.X-1, b.Max.Y-1); c != dark {
+		t.Errorf("Expected the extended area to take the canvas background, got %v", c)
+	}
+	if textColorOn(dark) == textColorOn(white) {
+		t.Error("Expected a light text color on dark backgrounds")
+	}
+
+	empty := &Legend{Title: "Legend", Position: WINDROSE_SE}
+	if result, _ := empty.Draw(img, white, ""); result != img {
+		t.Error("Expected a legend without entries to leave the image unchanged")
+	}
+}
+
+func TestTitleBlockDraw(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 200, 100))
+	titleBlock := &TitleBlock{
+		Title:    "Order processing",
+		Fields:   []TitleBlockField{{Name: "Version", Value: "1.4"}},
+		Position: WINDROSE_N,
+	}
+	dark := color.RGBA{35, 47, 62, 255}
+	result, err := titleBlock.Draw(img, dark, "")
+	if err != nil {
+		t.Fatalf("Draw failed: %v", err)
+	}
+	if result.Bounds().Dy() <= 100 {
+		t.Errorf("Expected the image to be extended above the diagram, got %v", result.Bounds())
+	}
+	if c := result.RGBAAt(0, 0); c != dark {
+		t.Errorf("Expected the title block to be drawn on the canvas background, got %v", c)
+	}
+	empty := &TitleBlock{Position: WINDROSE_N}
+	if result, _ := empty.Draw(img, dark, ""); result != img {
+		t.Error("Expected an empty title block to leave the image unchanged")
+	}
+}
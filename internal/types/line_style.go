@@ -0,0 +1,205 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package types
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"math"
+
+	"github.com/awslabs/diagram-as-code/internal/vector"
+)
+
+const (
+	LINE_STYLE_NORMAL = "normal"
+	LINE_STYLE_SOLID  = "solid"
+	LINE_STYLE_DASHED = "dashed"
+	LINE_STYLE_DOTTED = "dotted"
+	LINE_STYLE_DOUBLE = "double"
+)
+
+// stroke describes how a line is drawn, independently of the output format:
+// the perpendicular offsets of the parallel lines it is made of (one per pixel of width)
+// and the dash pattern of alternating drawn/skipped lengths, empty for a continuous line.
+type stroke struct {
+	offsets []float64
+	pattern []float64
+	restart bool // The pattern restarts at each segment and hops are solid, as dashed lines always were
+}
+
+// solidStroke returns a continuous stroke of the given width, used for arrow heads
+func solidStroke(width int) stroke {
+	offsets := make([]float64, 0, width)
+	for j := 0; j < width; j++ {
+		offsets = append(offsets, float64(j)-float64(width-1)/2)
+	}
+	return stroke{offsets: offsets}
+}
+
+// lineStroke returns the stroke of the link line from its LineStyle and DashPattern
+func (l *Link) lineStroke() stroke {
+	s := solidStroke(l.LineWidth)
+	if l.LineStyle == LINE_STYLE_DOUBLE {
+		// Two lines of the link width, separated by a gap of the same width
+		s.offsets = []float64{}
+		for _, offset := range solidStroke(l.LineWidth).offsets {
+			s.offsets = append(s.offsets, offset-float64(l.LineWidth), offset+float64(l.LineWidth))
+		}
+	}
+	switch {
+	case len(l.DashPattern) > 0:
+		s.pattern = l.DashPattern
+	case l.LineStyle == LINE_STYLE_DASHED:
+		s.pattern = []float64{6, 3}
+		s.restart = true
+	case l.LineStyle == LINE_STYLE_DOTTED:
+		dot := float64(max(l.LineWidth, 1))
+		s.pattern = []float64{dot, dot * 2}
+	}
+	return s
+}
+
+// visible reports whether the stroke is drawn at the given distance along the line
+func (s stroke) visible(distance float64) bool {
+	if len(s.pattern) == 0 {
+		return true
+	}
+	pattern := s.pattern
+	if len(pattern)%2 == 1 {
+		// An odd pattern is repeated so that drawn and skipped lengths alternate
+		pattern = append(append([]float64{}, pattern...), pattern...)
+	}
+	total := 0.0
+	for _, v := range pattern {
+		total += v
+	}
+	if total <= 0 {
+		return true
+	}
+	position := math.Mod(distance, total)
+	for i, v := range pattern {
+		if position < v {
+			return i%2 == 0
+		}
+		position -= v
+	}
+	return true
+}
+
+func (l *Link) SetLineStyle(s string) {
+	l.LineStyle = s
+}
+
+// SetDashPattern sets a custom dash pattern of alternating drawn/skipped lengths in pixels.
+// It overrides the pattern of the dashed and dotted line styles.
+func (l *Link) SetDashPattern(pattern []float64) error {
+	total := 0.0
+	for _, v := range pattern {
+		if v < 0 {
+			return fmt.Errorf("dash pattern must not contain negative lengths: %v", pattern)
+		}
+		total += v
+	}
+	if len(pattern) > 0 && total == 0 {
+		return fmt.Errorf("dash pattern must contain a positive length: %v", pattern)
+	}
+	l.DashPattern = pattern
+	return nil
+}
+
+// drawStroke draws a straight line with the given stroke. The dash pattern starts at
+// the given distance, and the distance at the end of the line is returned so that
+// the pattern continues over the next segment.
+func (l *Link) drawStroke(img *image.RGBA, sourcePt, targetPt image.Point, s stroke, distance float64) float64 {
+	sourceVec := vector.New(float64(sourcePt.X), float64(sourcePt.Y))
+	targetVec := vector.New(float64(targetPt.X), float64(targetPt.Y))
+	direction := targetVec.Sub(sourceVec)
+	length := direction.Length()
+
+	if length == 0 {
+		return distance
+	}
+
+	unitDir := direction.Normalize()
+	perpDir := unitDir.Perpendicular()
+
+	for i := 0; i < int(length); i++ {
+		if !s.visible(distance + float64(i)) {
+			continue
+		}
+		pos := sourceVec.Add(unitDir.Scale(float64(i)))
+		for _, offset := range s.offsets {
+			finalPos := pos.Add(perpDir.Scale(offset))
+			l.drawNeighborsDot(img, finalPos.X, finalPos.Y)
+		}
+	}
+	return distance + length
+}
+
+// fillPolygon fills a convex polygon with the line color, antialiased by 4x4 supersampling
+func (l *Link) fillPolygon(img *image.RGBA, pts []vector.Vector) {
+	minX, minY := math.Inf(1), math.Inf(1)
+	maxX, maxY := math.Inf(-1), math.Inf(-1)
+	for _, p := range pts {
+		minX, minY = math.Min(minX, p.X), math.Min(minY, p.Y)
+		maxX, maxY = math.Max(maxX, p.X), math.Max(maxY, p.Y)
+	}
+	inside := func(x, y float64) bool {
+		sign := 0.0
+		for i := range pts {
+			a, b := pts[i], pts[(i+1)%len(pts)]
+			cross := b.Sub(a).Cross(vector.New(x, y).Sub(a))
+			if cross == 0 {
+				continue
+			}
+			if sign == 0 {
+				sign = cross
+			} else if (cross > 0) != (sign > 0) {
+				return false
+			}
+		}
+		return true
+	}
+	bounds := image.Rect(int(math.Floor(minX)), int(math.Floor(minY)), int(math.Ceil(maxX))+1, int(math.Ceil(maxY))+1).Intersect(img.Bounds())
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			hits := 0
+			for sy := 0; sy < 4; sy++ {
+				for sx := 0; sx < 4; sx++ {
+					// Lines are drawn centered on pixels, so (x, y) is the center of pixel x, y
+					if inside(float64(x)+(float64(sx)+0.5)/4-0.5, float64(y)+(float64(sy)+0.5)/4-0.5) {
+						hits++
+					}
+				}
+			}
+			if hits == 0 {
+				continue
+			}
+			c := l.lineColor
+			c.A = uint8(float64(l.lineColor.A) * float64(hits) / 16)
+			img.Set(x, y, _blend_color(img.At(x, y), c))
+		}
+	}
+}
+
+// fillCircle fills an antialiased circle, sampling each pixel at its center
+func fillCircle(img *image.RGBA, center vector.Vector, radius float64, fill color.RGBA) {
+	bounds := image.Rect(
+		int(math.Floor(center.X-radius-1)), int(math.Floor(center.Y-radius-1)),
+		int(math.Ceil(center.X+radius+1)), int(math.Ceil(center.Y+radius+1)),
+	).Intersect(img.Bounds())
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			d := vector.New(float64(x)+0.5, float64(y)+0.5).Sub(center).Length()
+			coverage := math.Min(math.Max(radius+0.5-d, 0), 1)
+			if coverage == 0 {
+				continue
+			}
+			c := fill
+			c.A = uint8(float64(fill.A) * coverage)
+			img.Set(x, y, _blend_color(img.At(x, y), c))
+		}
+	}
+}
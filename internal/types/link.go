@@ -8,18 +8,14 @@ import (
 	"fmt"
 	"image"
 	"image/color"
-	"io"
 	"math"
 	"os"
-	"strings"
 
 	log "github.com/sirupsen/logrus"
 
 	fontPath "github.com/awslabs/diagram-as-code/internal/font"
 	"github.com/awslabs/diagram-as-code/internal/vector"
-	"github.com/golang/freetype/truetype"
 	"golang.org/x/image/font"
-	"golang.org/x/image/font/gofont/goregular"
 	"golang.org/x/image/math/fixed"
 )
 
@@ -27,7 +23,7 @@ type LINK_LABEL_TYPE int
 
 const (
 	LINK_LABEL_TYPE_HORIZONTAL LINK_LABEL_TYPE = iota
-	// [TODO] LINK_LABEL_TYPE_ALONG_PATH
+	LINK_LABEL_TYPE_ALONG_PATH
 )
 
 // Segment represents a line segment for overlap detection
@@ -40,24 +36,34 @@ type Segment struct {
 // Global slice to track all convergence segments
 var allConvergenceSegments []Segment
 
-// ResetConvergencePointSegments clears the global segment tracking
+// ResetConvergencePointSegments clears the global segment tracking and the link bundles
 func ResetConvergencePointSegments() {
 	allConvergenceSegments = []Segment{}
+	linkBundles = map[linkBundleKey]*linkBundle{}
 }
 
 type Link struct {
-	Source          *Resource
-	SourcePosition  Windrose
-	SourceArrowHead ArrowHead
-	Target          *Resource
-	TargetPosition  Windrose
-	TargetArrowHead ArrowHead
-	Type            string
-	LineWidth       int
-	LineStyle       string
-	Labels          LinkLabels
-	drawn           bool
-	lineColor       color.RGBA
+	Source           *Resource
+	SourcePosition   Windrose
+	SourceArrowHead  ArrowHead
+	Target           *Resource
+	TargetLink       *Link // Link this link ends on instead of Target
+	TargetPosition   Windrose
+	TargetArrowHead  ArrowHead
+	Type             string
+	LineWidth        int
+	LineStyle        string
+	DashPattern      []float64 // Alternating drawn/skipped lengths, overrides the LineStyle pattern
+	Labels           LinkLabels
+	Waypoints        []Waypoint
+	Step             int // Sequence number drawn as a badge, 0 for none
+	stepPosition     string
+	drawn            bool
+	lineColor        color.RGBA
+	path             []image.Point // Path of the drawn link, used to place the step badge
+	dashDistance     float64       // Distance drawn so far, so that dashes continue over bends
+	sourceAttachment *image.Point  // Point on the source border resolved from WINDROSE_NEAREST
+	targetAttachment *image.Point  // Point on the target border resolved from WINDROSE_NEAREST
 }
 
 type LinkLabels struct {
@@ -70,10 +76,15 @@ type LinkLabels struct {
 }
 
 type LinkLabel struct {
-	Type  LINK_LABEL_TYPE
-	Title string
-	Color *color.RGBA
-	Font  string
+	Type        LINK_LABEL_TYPE
+	Title       string
+	Color       *color.RGBA
+	FillColor   *color.RGBA // Background of along-path labels
+	Font        string
+	FontSize    float64 // 0 means the default size
+	FontWeight  string  // normal / bold
+	LineSpacing int     // Space between lines
+	MaxWidth    int     // Width the title is wrapped to (0 means no wrapping)
 }
 
 type ArrowHead struct {
@@ -98,7 +109,7 @@ func (l Link) Init(source *Resource, sourcePosition Windrose, sourceArrowHead Ar
 	return &gl
 }
 
-// ResolveAutoPositions converts WINDROSE_AUTO to actual positions after layout is complete
+// ResolveAutoPositions converts WINDROSE_AUTO and WINDROSE_NEAREST to actual positions after layout is complete
 func (l *Link) ResolveAutoPositions() error {
 	if l.SourcePosition == WINDROSE_AUTO || l.TargetPosition == WINDROSE_AUTO {
 		// Check for uninitialized resources
@@ -116,6 +127,12 @@ func (l *Link) ResolveAutoPositions() error {
 			l.TargetPosition = autoTargetPos
 		}
 	}
+	if l.SourcePosition == WINDROSE_NEAREST || l.TargetPosition == WINDROSE_NEAREST {
+		if l.Source.bindings == nil || l.Target.bindings == nil {
+			return fmt.Errorf("cannot calculate nearest positions for link: source or target resource is not properly initialized (possibly orphaned from Canvas hierarchy)")
+		}
+		l.resolveNearestPositions()
+	}
 	return nil
 }
 
@@ -123,8 +140,8 @@ func (l *Link) SetType(s string) {
 	l.Type = s
 }
 
-func (l *Link) SetLineStyle(s string) {
-	l.LineStyle = s
+func (l *Link) GetLineColor() color.RGBA {
+	return l.lineColor
 }
 
 func (l *Link) drawNeighborsDot(img *image.RGBA, x, y float64) {
@@ -145,30 +162,11 @@ func (l *Link) drawNeighborsDot(img *image.RGBA, x, y float64) {
 }
 
 func (l *Link) drawLine(img *image.RGBA, sourcePt image.Point, targetPt image.Point) {
-	sourceVec := vector.New(float64(sourcePt.X), float64(sourcePt.Y))
-	targetVec := vector.New(float64(targetPt.X), float64(targetPt.Y))
-	direction := targetVec.Sub(sourceVec)
-	length := direction.Length()
-
-	if length == 0 {
-		return
-	}
-
-	unitDir := direction.Normalize()
-	perpDir := unitDir.Perpendicular()
-
-	for i := 0; i < int(length); i++ {
-		pos := sourceVec.Add(unitDir.Scale(float64(i)))
-
-		if l.LineStyle == "dashed" && i%9 > 5 {
-			continue
-		}
-		for j := 0; j < l.LineWidth; j++ {
-			offset := float64(j) - float64(l.LineWidth-1)/2
-			finalPos := pos.Add(perpDir.Scale(offset))
-			l.drawNeighborsDot(img, finalPos.X, finalPos.Y)
-		}
+	s := l.lineStroke()
+	if s.restart {
+		l.dashDistance = 0
 	}
+	l.dashDistance = l.drawStroke(img, sourcePt, targetPt, s, l.dashDistance)
 }
 
 func (l *Link) prepareFontFace(label *LinkLabel, parent1, parent2 *Resource) (font.Face, error) {
@@ -192,45 +190,15 @@ func (l *Link) prepareFontFace(label *LinkLabel, parent1, parent2 *Resource) (fo
 		} else if parent2 != nil && parent2.labelFont != "" {
 			label.Color = parent2.labelColor
 		} else {
-			label.Color = &color.RGBA{0, 0, 0, 255}
+			textColor := defaultTextColor
+			label.Color = &textColor
 		}
 	}
-	var ttfBytes []byte
-	if label.Font == "goregular" || label.Font == "" {
-		// Use Go-fonts instead system fonts
-		ttfBytes = goregular.TTF
-	} else {
-		f, err := os.Open(label.Font)
-		if err != nil {
-			return nil, fmt.Errorf("failed to open font file: %w", err)
-		}
-		defer func() {
-			if closeErr := f.Close(); closeErr != nil {
-				log.Warnf("Failed to close font file: %v", closeErr)
-			}
-		}()
-
-		ttfBytes, err = io.ReadAll(f)
-		if err != nil {
-			return nil, fmt.Errorf("failed to read font file: %w", err)
-		}
-	}
-
-	ft, err := truetype.Parse(ttfBytes)
-	if err != nil {
-		return nil, fmt.Errorf("failed to parse font: %w", err)
+	size := 24.0
+	if label.FontSize > 0 {
+		size = label.FontSize
 	}
-
-	opt := truetype.Options{
-		Size:              24,
-		DPI:               0,
-		Hinting:           0,
-		GlyphCacheEntries: 0,
-		SubPixelsX:        0,
-		SubPixelsY:        0,
-	}
-
-	return truetype.NewFace(ft, &opt), nil
+	return loadFontFace(label.Font, size, label.FontWeight)
 }
 
 func (l *Link) computeLabelPos(t, d, label vector.Vector) vector.Vector {
@@ -252,7 +220,8 @@ func (l *Link) computeLabelPos(t, d, label vector.Vector) vector.Vector {
 }
 
 func (l *Link) drawLabel(img *image.RGBA, pos Windrose, source, target *Resource, sourcePt, targetPt image.Point, side string, label *LinkLabel) error {
-	if label == nil {
+	if label == nil || label.Type == LINK_LABEL_TYPE_ALONG_PATH {
+		// Along-path labels are drawn by drawAlongPathLabels
 		return nil
 	}
 	sourceVec := vector.New(float64(sourcePt.X), float64(sourcePt.Y))
@@ -281,8 +250,11 @@ func (l *Link) drawLabel(img *image.RGBA, pos Windrose, source, target *Resource
 	if err != nil {
 		return fmt.Errorf("failed to prepare font face for link label: %w", err)
 	}
-	texts := strings.Split(label.Title, "\n")
-	for _, line := range texts {
+	texts := wrapText(fontFace, label.Title, label.MaxWidth)
+	for i, line := range texts {
+		if i > 0 {
+			textHeight += label.LineSpacing
+		}
 		textBindings, _ := font.BoundString(fontFace, line)
 		textWidth = max(textWidth, textBindings.Max.X.Ceil()-textBindings.Min.X.Ceil())
 		textHeight += textBindings.Max.Y.Ceil() - textBindings.Min.Y.Ceil()
@@ -320,7 +292,7 @@ func (l *Link) drawLabel(img *image.RGBA, pos Windrose, source, target *Resource
 				Dot:  point,
 			}
 			d.DrawString(line)
-			lineOffset += lineOffset + textBindings.Max.Y - textBindings.Min.Y
+			lineOffset += textBindings.Max.Y - textBindings.Min.Y + fixed.I(label.LineSpacing)
 		}
 	}
 	return nil
@@ -368,6 +340,13 @@ func (l *Link) drawArrowHead(img *image.RGBA, arrowPt image.Point, originPt imag
 	at1 := image.Point{int(math.Round(at1Vec.X)), int(math.Round(at1Vec.Y))}
 	at2 := image.Point{int(math.Round(at2Vec.X)), int(math.Round(at2Vec.Y))}
 
+	// Arrow heads are drawn with a solid line whatever the line style
+	solid := solidStroke(l.LineWidth)
+	unitDir := direction.Normalize()
+	depth := arrowHead.Length * _a / _b     // Distance from the tip to the base of the arrow head
+	halfWidth := arrowHead.Length * _c / _b // Half of the width of the base
+	normal := unitDir.Perpendicular()
+
 	switch arrowHead.Type {
 	case "Default":
 		log.Info("Default Arrow Head drawing")
@@ -376,12 +355,32 @@ func (l *Link) drawArrowHead(img *image.RGBA, arrowPt image.Point, originPt imag
 			a := arrowPt.Mul(i)
 			b := a.Add(at1.Mul(al - i)).Div(al)
 			c := a.Add(at2.Mul(al - i)).Div(al)
-			l.drawLine(img, b, c)
+			l.drawStroke(img, b, c, solid, 0)
 		}
 	case "Open":
 		log.Info("Open Arrow Head drawing")
-		l.drawLine(img, arrowPt, at1)
-		l.drawLine(img, arrowPt, at2)
+		l.drawStroke(img, arrowPt, at1, solid, 0)
+		l.drawStroke(img, arrowPt, at2, solid, 0)
+	case "Diamond":
+		log.Info("Diamond Arrow Head drawing")
+		middle := arrowVec.Sub(unitDir.Scale(depth))
+		l.fillPolygon(img, []vector.Vector{
+			arrowVec,
+			middle.Add(normal.Scale(halfWidth)),
+			arrowVec.Sub(unitDir.Scale(depth * 2)),
+			middle.Sub(normal.Scale(halfWidth)),
+		})
+	case "Circle":
+		log.Info("Circle Arrow Head drawing")
+		// Lines are drawn centered on pixels, so the center is moved to the pixel center
+		fillCircle(img, arrowVec.Sub(unitDir.Scale(halfWidth)).Add(vector.New(0.5, 0.5)), halfWidth, l.lineColor)
+	case "CrowsFoot":
+		log.Info("Crow's foot Arrow Head drawing")
+		// Three prongs spreading from a point on the line to the resource
+		fork := toPoint(arrowVec.Sub(unitDir.Scale(depth * 2)))
+		l.drawStroke(img, fork, toPoint(arrowVec.Add(normal.Scale(halfWidth))), solid, 0)
+		l.drawStroke(img, fork, arrowPt, solid, 0)
+		l.drawStroke(img, fork, toPoint(arrowVec.Sub(normal.Scale(halfWidth))), solid, 0)
 	}
 }
 
@@ -394,11 +393,14 @@ func (l *Link) Draw(img *image.RGBA) error {
 	}
 
 	log.Info("Link Drawing")
+	l.dashDistance = 0
 	sourcePt := l.calcPositionWithOffset(source.GetBindings(), l.SourcePosition, l.Source, true)
 	targetPt := l.calcPositionWithOffset(target.GetBindings(), l.TargetPosition, l.Target, false)
 
+	// Control points of orthogonal links, routed once and shared by the path and the labels
+	var controlPts []image.Point
 	if l.Type == "" || l.Type == "straight" {
-		l.drawLine(img, sourcePt, targetPt)
+		l.drawSegment(img, sourcePt, targetPt)
 		l.drawArrowHead(img, sourcePt, targetPt, l.SourceArrowHead)
 		l.drawArrowHead(img, targetPt, sourcePt, l.TargetArrowHead)
 		if err := l.drawLabel(img, l.SourcePosition, l.Source, l.Target, sourcePt, targetPt, "Right", l.Labels.SourceRight); err != nil {
@@ -413,12 +415,35 @@ func (l *Link) Draw(img *image.RGBA) error {
 		if err := l.drawLabel(img, l.TargetPosition, l.Target, l.Source, targetPt, sourcePt, "Right", l.Labels.TargetLeft); err != nil {
 			return fmt.Errorf("failed to draw target left label: %w", err)
 		}
-	} else if l.Type == "orthogonal" {
-		controlPts := l.calculateOrthogonalPath(sourcePt, targetPt)
+	} else if l.Type == "curved" {
+		c1, c2 := l.calculateCurveControlPoints(sourcePt, targetPt)
+		l.drawCurve(img,
+			vector.New(float64(sourcePt.X), float64(sourcePt.Y)), c1, c2,
+			vector.New(float64(targetPt.X), float64(targetPt.Y)))
+
+		// Arrow heads and labels follow the tangents at both ends of the curve
+		sourceTangentPt := toPoint(c1)
+		targetTangentPt := toPoint(c2)
+		l.drawArrowHead(img, sourcePt, sourceTangentPt, l.SourceArrowHead)
+		l.drawArrowHead(img, targetPt, targetTangentPt, l.TargetArrowHead)
+		if err := l.drawLabel(img, l.SourcePosition, l.Source, l.Target, sourcePt, sourceTangentPt, "Right", l.Labels.SourceRight); err != nil {
+			return fmt.Errorf("failed to draw source right label: %w", err)
+		}
+		if err := l.drawLabel(img, l.SourcePosition, l.Source, l.Target, sourcePt, sourceTangentPt, "Left", l.Labels.SourceLeft); err != nil {
+			return fmt.Errorf("failed to draw source left label: %w", err)
+		}
+		if err := l.drawLabel(img, l.TargetPosition, l.Target, l.Source, targetPt, targetTangentPt, "Left", l.Labels.TargetRight); err != nil {
+			return fmt.Errorf("failed to draw target right label: %w", err)
+		}
+		if err := l.drawLabel(img, l.TargetPosition, l.Target, l.Source, targetPt, targetTangentPt, "Right", l.Labels.TargetLeft); err != nil {
+			return fmt.Errorf("failed to draw target left label: %w", err)
+		}
+	} else if l.isOrthogonal() {
+		controlPts = l.orthogonalControlPoints(sourcePt, targetPt)
 
 		// Draw the path
 		if len(controlPts) >= 1 {
-			l.drawLine(img, sourcePt, controlPts[0])
+			l.drawPath(img, append(append([]image.Point{sourcePt}, controlPts...), targetPt))
 			l.drawArrowHead(img, sourcePt, controlPts[0], l.SourceArrowHead)
 			if err := l.drawLabel(img, l.SourcePosition, l.Source, l.Target, sourcePt, controlPts[0], "Right", l.Labels.SourceRight); err != nil {
 				return fmt.Errorf("failed to draw source right label: %w", err)
@@ -426,10 +451,6 @@ func (l *Link) Draw(img *image.RGBA) error {
 			if err := l.drawLabel(img, l.SourcePosition, l.Source, l.Target, sourcePt, controlPts[0], "Left", l.Labels.SourceLeft); err != nil {
 				return fmt.Errorf("failed to draw source left label: %w", err)
 			}
-			for i := 0; i < len(controlPts)-1; i++ {
-				l.drawLine(img, controlPts[i], controlPts[i+1])
-			}
-			l.drawLine(img, controlPts[len(controlPts)-1], targetPt)
 			l.drawArrowHead(img, targetPt, controlPts[len(controlPts)-1], l.TargetArrowHead)
 			if err := l.drawLabel(img, l.TargetPosition, l.Target, l.Source, targetPt, controlPts[len(controlPts)-1], "Left", l.Labels.TargetRight); err != nil {
 				return fmt.Errorf("failed to draw target right label: %w", err)
@@ -438,7 +459,7 @@ func (l *Link) Draw(img *image.RGBA) error {
 				return fmt.Errorf("failed to draw target left label: %w", err)
 			}
 		} else {
-			l.drawLine(img, sourcePt, targetPt)
+			l.drawSegment(img, sourcePt, targetPt)
 			l.drawArrowHead(img, sourcePt, targetPt, l.SourceArrowHead)
 			l.drawArrowHead(img, targetPt, sourcePt, l.TargetArrowHead)
 			if err := l.drawLabel(img, l.SourcePosition, l.Source, l.Target, sourcePt, targetPt, "Right", l.Labels.SourceRight); err != nil {
@@ -503,15 +524,20 @@ func (l *Link) Draw(img *image.RGBA) error {
 	}
 
 	// Draw auto-positioned labels
-	var controlPts []image.Point
-	if l.Type == "orthogonal" {
-		controlPts = l.calculateOrthogonalPath(sourcePt, targetPt)
-	}
-
 	autoPt1, autoPt2 := l.calculateAutoLabelPoints(sourcePt, targetPt, controlPts)
 
 	// Determine appropriate pos based on line direction
 	autoPos := Windrose(4) // Default: East
+	if l.Type == "curved" {
+		// The curve is rarely axis-aligned at its middle, so use the dominant axis of the tangent
+		autoPt1, autoPt2 = l.calculateCurveLabelPoints(sourcePt, targetPt)
+		dx, dy := autoPt2.X-autoPt1.X, autoPt2.Y-autoPt1.Y
+		if abs(dx) >= abs(dy) {
+			autoPt2.Y = autoPt1.Y
+		} else {
+			autoPt2.X = autoPt1.X
+		}
+	}
 	if autoPt1.Y == autoPt2.Y {
 		// Horizontal line: use East/West for vertical label placement
 		if autoPt1.X < autoPt2.X {
@@ -529,7 +555,7 @@ func (l *Link) Draw(img *image.RGBA) error {
 	}
 
 	// For orthogonal links, check acute angle sides and adjust label placement
-	if l.Type == "orthogonal" && len(controlPts) > 0 {
+	if l.isOrthogonal() && len(controlPts) > 0 {
 		// Create complete path including source and target points
 		fullPath := make([]image.Point, 0, len(controlPts)+2)
 		fullPath = append(fullPath, sourcePt)
@@ -603,10 +629,56 @@ func (l *Link) Draw(img *image.RGBA) error {
 		}
 	}
 
+	// Draw along-path labels on top of the link
+	var sourceAnchor, targetAnchor, autoAnchor labelAnchor
+	var drawnPath []image.Point
+	if l.Type == "curved" {
+		sourceAnchor, targetAnchor, autoAnchor = l.curveLabelAnchors(sourcePt, targetPt)
+		drawnPath = l.curvePolyline(sourcePt, targetPt)
+	} else {
+		drawnPath = append(append([]image.Point{sourcePt}, controlPts...), targetPt)
+		sourceAnchor, targetAnchor, autoAnchor = pathLabelAnchors(drawnPath)
+	}
+	if err := l.drawAlongPathLabels(img, sourceAnchor, targetAnchor, autoAnchor); err != nil {
+		return err
+	}
+
+	// Links drawn later jump over this one where they cross
+	l.recordLinkSegments(mergeCollinearPoints(drawnPath))
+	l.path = drawnPath
+
 	l.drawn = true
 	return nil
 }
 
+// isOrthogonal reports whether the link is drawn with horizontal and vertical segments only
+func (l *Link) isOrthogonal() bool {
+	return l.Type == "orthogonal" || l.Type == "orthogonal-routed"
+}
+
+// orthogonalControlPoints returns the control points of an orthogonal link.
+// Links with waypoints pass through them in order, and bundled links follow their bundle. Otherwise orthogonal-routed links
+// avoid other resources and fall back to the convergent path when no route is found.
+func (l *Link) orthogonalControlPoints(sourcePt, targetPt image.Point) []image.Point {
+	if len(l.Waypoints) > 0 {
+		controlPts, err := l.calculateWaypointPath(sourcePt, targetPt)
+		if err == nil {
+			return controlPts
+		}
+		log.Warnf("Ignoring waypoints of link from %v to %v: %v", sourcePt, targetPt, err)
+	}
+	if controlPts, ok := l.bundledControlPoints(sourcePt, targetPt); ok {
+		return controlPts
+	}
+	if l.Type == "orthogonal-routed" {
+		if controlPts, ok := l.calculateRoutedPath(sourcePt, targetPt); ok {
+			return controlPts
+		}
+		log.Warnf("Falling back to orthogonal path for link from %v to %v", sourcePt, targetPt)
+	}
+	return l.calculateOrthogonalPath(sourcePt, targetPt)
+}
+
 // calculateOrthogonalPath generates control points using convergent approach
 func (l *Link) calculateOrthogonalPath(sourcePt, targetPt image.Point) []image.Point {
 	log.Infof("=== Convergent Orthogonal Path Calculation ===")
@@ -1717,10 +1789,18 @@ func findChildAncestorInLCA(lca *Resource, resource *Resource) *Resource {
 }
 
 func (l *Link) calcPositionWithOffset(bindings image.Rectangle, position Windrose, resource *Resource, isSource bool) image.Point {
+	// Points attached to the nearest border are already apart from each other
+	if isSource && l.sourceAttachment != nil {
+		return *l.sourceAttachment
+	}
+	if !isSource && l.targetAttachment != nil {
+		return *l.targetAttachment
+	}
+
 	// Adjust bindings for SSE/S/SSW positions to include title height
 	bindingsWithTitle := bindings
 	if position == WINDROSE_SSE || position == WINDROSE_S || position == WINDROSE_SSW {
-		fontFace, err := resource.prepareFontFace(len(resource.children) > 0, nil)
+		fontFace, err := resource.prepareFontFace(len(resource.children) > 0 || resource.collapsed, nil)
 		if err == nil {
 			_, titleHeight := resource.calculateTitleSize(fontFace)
 			bindingsWithTitle.Max.Y += titleHeight
@@ -2193,7 +2273,7 @@ func (l *Link) findBestSegmentForSideWithPosition(controlPts []image.Point, side
 
 // calculateAutoLabelPoints calculates points for auto label positioning
 func (l *Link) calculateAutoLabelPoints(sourcePt, targetPt image.Point, controlPts []image.Point) (image.Point, image.Point) {
-	if l.Type == "orthogonal" && len(controlPts) > 0 {
+	if l.isOrthogonal() && len(controlPts) > 0 {
 		// Create complete path including source and target points
 		fullPath := make([]image.Point, 0, len(controlPts)+2)
 		fullPath = append(fullPath, sourcePt)
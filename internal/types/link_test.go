@@ -4,6 +4,7 @@ import (
 	"image"
 	"image/color"
 	"math"
+	"reflect"
 	"testing"
 
 	"github.com/awslabs/diagram-as-code/internal/vector"
@@ -3983,3 +3984,515 @@ func TestAutoCalculatePositions_BorderChild(t *testing.T) {
 	}
 	t.Logf("Test 3 passed: NATGW->IGW, both use inside positions")
 }
+
+func TestOrthogonalRoutedPathAvoidsResources(t *testing.T) {
+	canvas := &Resource{}
+	source := &Resource{bindings: &image.Rectangle{Min: image.Point{0, 0}, Max: image.Point{64, 64}}}
+	obstacle := &Resource{bindings: &image.Rectangle{Min: image.Point{150, 0}, Max: image.Point{214, 64}}}
+	target := &Resource{bindings: &image.Rectangle{Min: image.Point{300, 0}, Max: image.Point{364, 64}}}
+	for _, r := range []*Resource{source, obstacle, target} {
+		canvas.AddChild(r)
+	}
+
+	link := &Link{
+		Source:         source,
+		SourcePosition: 4, // E
+		Target:         target,
+		TargetPosition: 12, // W
+		Type:           "orthogonal-routed",
+	}
+	sourcePt := image.Point{64, 32}
+	targetPt := image.Point{300, 32}
+
+	controlPts, ok := link.calculateRoutedPath(sourcePt, targetPt)
+	if !ok {
+		t.Fatalf("Expected a route from %v to %v", sourcePt, targetPt)
+	}
+	t.Logf("Control points: %v", controlPts)
+
+	path := append(append([]image.Point{sourcePt}, controlPts...), targetPt)
+	blocked := obstacle.GetBindings()
+	for i := 0; i < len(path)-1; i++ {
+		if path[i].X != path[i+1].X && path[i].Y != path[i+1].Y {
+			t.Errorf("Non-orthogonal segment from %v to %v", path[i], path[i+1])
+		}
+		if segmentCrossesRect(path[i], path[i+1], blocked) {
+			t.Errorf("Segment from %v to %v crosses resource %v", path[i], path[i+1], blocked)
+		}
+	}
+	if path[1].Y != sourcePt.Y || path[1].X <= sourcePt.X {
+		t.Errorf("Expected the path to leave the source to the east, got %v", path[1])
+	}
+	if last := path[len(path)-2]; last.Y != targetPt.Y || last.X >= targetPt.X {
+		t.Errorf("Expected the path to enter the target from the west, got %v", last)
+	}
+
+	// Without obstacles, the routed path is a straight line
+	canvas.children = []*Resource{source, target}
+	controlPts, ok = link.calculateRoutedPath(sourcePt, targetPt)
+	if !ok || len(controlPts) != 0 {
+		t.Errorf("Expected a straight route, got %v (ok=%v)", controlPts, ok)
+	}
+}
+
+func TestCurvedLinkTangents(t *testing.T) {
+	link := &Link{
+		SourcePosition: 4,  // E
+		TargetPosition: 12, // W
+		Type:           "curved",
+		LineWidth:      1,
+	}
+	sourcePt := image.Point{100, 100}
+	targetPt := image.Point{300, 200}
+
+	c1, c2 := link.calculateCurveControlPoints(sourcePt, targetPt)
+	if c1.Y != 100 || c1.X <= 100 {
+		t.Errorf("Expected the curve to leave the source to the east, got control point %v", c1)
+	}
+	if c2.Y != 200 || c2.X >= 300 {
+		t.Errorf("Expected the curve to enter the target from the west, got control point %v", c2)
+	}
+
+	p0 := vector.New(100, 100)
+	p3 := vector.New(300, 200)
+	if got := bezierPoint(p0, c1, c2, p3, 0); got != p0 {
+		t.Errorf("Expected the curve to start at %v, got %v", p0, got)
+	}
+	if got := bezierPoint(p0, c1, c2, p3, 1); got.Sub(p3).Length() > 1e-9 {
+		t.Errorf("Expected the curve to end at %v, got %v", p3, got)
+	}
+
+	// The tangent at the target points into the target, so arrow heads are drawn along it
+	if tangent := bezierTangent(p0, c1, c2, p3, 1).Normalize(); tangent.X < 0.99 {
+		t.Errorf("Expected the end tangent to point east, got %v", tangent)
+	}
+
+	mid, ahead := link.calculateCurveLabelPoints(sourcePt, targetPt)
+	if mid != (image.Point{200, 150}) {
+		t.Errorf("Expected the label anchor at the middle of the curve, got %v", mid)
+	}
+	if ahead.X <= mid.X {
+		t.Errorf("Expected the label direction to follow the curve, got %v -> %v", mid, ahead)
+	}
+
+	img := image.NewRGBA(image.Rect(0, 0, 400, 300))
+	link.lineColor = color.RGBA{0, 0, 0, 255}
+	link.drawCurve(img, p0, c1, c2, p3)
+	if _, _, _, a := img.At(200, 150).RGBA(); a == 0 {
+		t.Errorf("Expected the curve to be drawn through its middle point")
+	}
+}
+
+func TestWaypointPath(t *testing.T) {
+	canvas := &Resource{bindings: &image.Rectangle{Min: image.Point{10, 10}, Max: image.Point{500, 500}}}
+	source := &Resource{bindings: &image.Rectangle{Min: image.Point{20, 20}, Max: image.Point{84, 84}}}
+	via := &Resource{bindings: &image.Rectangle{Min: image.Point{200, 200}, Max: image.Point{264, 264}}}
+	target := &Resource{bindings: &image.Rectangle{Min: image.Point{400, 20}, Max: image.Point{464, 84}}}
+	for _, r := range []*Resource{source, via, target} {
+		canvas.AddChild(r)
+	}
+
+	link := &Link{
+		Source:         source,
+		SourcePosition: 8, // S
+		Target:         target,
+		TargetPosition: 8, // S
+		Type:           "orthogonal",
+		Waypoints: []Waypoint{
+			{Resource: via, Position: 8},   // S of via: (232, 264)
+			{Point: image.Point{440, 290}}, // (450, 300) on the image
+		},
+	}
+	sourcePt := image.Point{52, 84}
+	targetPt := image.Point{432, 84}
+
+	controlPts := link.orthogonalControlPoints(sourcePt, targetPt)
+	expected := []image.Point{
+		{52, 264},  // Leave the source downwards to the level of the S of via (232, 264)
+		{450, 264}, // Keep horizontal through via
+		{450, 300}, // Absolute waypoint
+		{432, 300}, // Enter the target from below
+	}
+	if !reflect.DeepEqual(controlPts, expected) {
+		t.Errorf("Expected control points %v, got %v", expected, controlPts)
+	}
+}
+
+func TestAlongPathLabel(t *testing.T) {
+	path := []image.Point{{0, 0}, {0, 50}, {200, 50}, {200, 80}}
+	source, target, auto := pathLabelAnchors(path)
+	if source.point != vector.New(0, 0) || source.direction != vector.New(0, 50) {
+		t.Errorf("Unexpected source anchor %+v", source)
+	}
+	if target.point != vector.New(200, 80) || target.direction != vector.New(0, 30) {
+		t.Errorf("Unexpected target anchor %+v", target)
+	}
+	if auto.point != vector.New(100, 50) || auto.direction != vector.New(200, 0) {
+		t.Errorf("Expected the auto anchor at the middle of the longest segment, got %+v", auto)
+	}
+
+	fill := color.RGBA{255, 255, 0, 255}
+	label := &LinkLabel{Type: LINK_LABEL_TYPE_ALONG_PATH, Title: "label", FillColor: &fill, Font: "goregular"}
+	link := &Link{LineWidth: 2, Labels: LinkLabels{AutoRight: label}}
+
+	// A vertical segment rotates the label box, so it is taller than wide
+	img := image.NewRGBA(image.Rect(0, 0, 300, 300))
+	anchor := labelAnchor{vector.New(150, 150), vector.New(0, -1)}
+	if err := link.drawAlongPathLabels(img, anchor, anchor, anchor); err != nil {
+		t.Fatalf("drawAlongPathLabels failed: %v", err)
+	}
+	filled := image.Rectangle{}
+	for y := 0; y < 300; y++ {
+		for x := 0; x < 300; x++ {
+			if c := img.RGBAAt(x, y); c.R == 255 && c.G == 255 && c.B == 0 {
+				filled = filled.Union(image.Rect(x, y, x+1, y+1))
+			}
+		}
+	}
+	if filled.Empty() {
+		t.Fatal("Expected the label background to be filled")
+	}
+	if filled.Dy() <= filled.Dx() {
+		t.Errorf("Expected a rotated label box, got %v", filled)
+	}
+	// Right-hand side of a line going up is east
+	if filled.Min.X < 150 {
+		t.Errorf("Expected the label on the right-hand side of the path, got %v", filled)
+	}
+}
+
+func TestLinkCrossing(t *testing.T) {
+	if err := SetLinkCrossing("unknown"); err == nil {
+		t.Error("Expected error for unknown link crossing style")
+	}
+	defer func() {
+		_ = SetLinkCrossing("")
+		ResetDrawnLinkSegments()
+	}()
+
+	earlier := &Link{LineWidth: 2}
+	later := &Link{LineWidth: 2, lineColor: color.RGBA{0, 0, 0, 255}}
+
+	ResetDrawnLinkSegments()
+	earlier.recordLinkSegments(mergeCollinearPoints([]image.Point{{0, 50}, {50, 50}, {100, 50}}))
+	if len(drawnLinkSegments) != 1 {
+		t.Fatalf("Expected collinear points to be merged into 1 segment, got %d", len(drawnLinkSegments))
+	}
+
+	crossings := later.findCrossings(image.Point{50, 0}, image.Point{50, 100})
+	if !reflect.DeepEqual(crossings, []float64{50}) {
+		t.Errorf("Expected a crossing at 50, got %v", crossings)
+	}
+	// Crossings at the ends of a segment are ignored
+	if crossings := later.findCrossings(image.Point{50, 50}, image.Point{50, 100}); len(crossings) != 0 {
+		t.Errorf("Expected no crossing at the end of the segment, got %v", crossings)
+	}
+	// A link does not cross itself
+	if crossings := earlier.findCrossings(image.Point{50, 0}, image.Point{50, 100}); len(crossings) != 0 {
+		t.Errorf("Expected no crossing with the same link, got %v", crossings)
+	}
+
+	tests := []struct {
+		style         string
+		drawnAtCenter bool
+		drawnOnHop    bool
+	}{
+		{"none", true, false},
+		{"gap", false, false},
+		{"jump", false, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.style, func(t *testing.T) {
+			if err := SetLinkCrossing(tt.style); err != nil {
+				t.Fatalf("SetLinkCrossing failed: %v", err)
+			}
+			img := image.NewRGBA(image.Rect(0, 0, 100, 100))
+			later.drawSegment(img, image.Point{50, 0}, image.Point{50, 100})
+			if _, _, _, a := img.At(50, 50).RGBA(); (a != 0) != tt.drawnAtCenter {
+				t.Errorf("Expected drawn at crossing = %v", tt.drawnAtCenter)
+			}
+			// The jump of a vertical segment bulges to the left
+			radius := int(later.crossingRadius())
+			if _, _, _, a := img.At(50-radius, 50).RGBA(); (a != 0) != tt.drawnOnHop {
+				t.Errorf("Expected drawn on hop = %v", tt.drawnOnHop)
+			}
+			if _, _, _, a := img.At(50, 10).RGBA(); a == 0 {
+				t.Error("Expected the segment to be drawn away from the crossing")
+			}
+		})
+	}
+}
+
+func TestLinkStep(t *testing.T) {
+	path := []image.Point{{0, 0}, {100, 0}, {100, 100}}
+	if length := pathLength(path); length != 200 {
+		t.Errorf("Expected path length 200, got %v", length)
+	}
+	if pt := pointAlongPath(path, 150); pt != vector.New(100, 50) {
+		t.Errorf("Expected (100, 50), got %v", pt)
+	}
+
+	source := new(Resource).Init()
+	target := new(Resource).Init()
+	link := new(Link).Init(source, WINDROSE_E, ArrowHead{}, target, WINDROSE_W, ArrowHead{}, 2, color.RGBA{255, 0, 0, 255})
+	if err := link.SetStep(1, "target"); err == nil {
+		t.Error("Expected error for unknown step position")
+	}
+	if err := link.SetStep(3, ""); err != nil {
+		t.Fatalf("SetStep failed: %v", err)
+	}
+
+	img := image.NewRGBA(image.Rect(0, 0, 120, 120))
+	link.path = path
+	if err := link.DrawStep(img, ""); err != nil {
+		t.Fatalf("DrawStep failed: %v", err)
+	}
+	// The badge is drawn at the middle of the path, with the number in white
+	hasFill, hasText := false, false
+	for y := 0; y < 12; y++ {
+		for x := 88; x < 112; x++ {
+			c := img.RGBAAt(x, y)
+			hasFill = hasFill || (c.R == 255 && c.G == 0 && c.B == 0)
+			hasText = hasText || (c.R > 200 && c.G > 200 && c.B > 200)
+		}
+	}
+	if !hasFill || !hasText {
+		t.Errorf("Expected a red badge with a white number at the middle of the path (fill=%v, text=%v)", hasFill, hasText)
+	}
+
+	dark := color.RGBA{35, 47, 62, 255}
+	legend, err := DrawStepLegend(img, []*Link{link}, dark, "")
+	if err != nil {
+		t.Fatalf("DrawStepLegend failed: %v", err)
+	}
+	b := legend.Bounds()
+	if b.Dy() <= img.Bounds().Dy() {
+		t.Errorf("Expected the legend to extend the image, got %v", b)
+	}
+	if c := legend.RGBAAt(b.Max.X-1, b.Max.Y-1); c != dark {
+		t.Errorf("Expected the legend to be drawn on the canvas background, got %v", c)
+	}
+
+	// Badges of numbers with several digits grow the same way on links and in the legend
+	face, err := stepBadgeFace("")
+	if err != nil {
+		t.Fatalf("Failed to prepare font face: %v", err)
+	}
+	if stepBadgeRadiusFor(face, "1") != stepBadgeRadius || stepBadgeRadiusFor(face, "1000") <= stepBadgeRadius {
+		t.Error("Expected the badge radius to grow with the number of digits only")
+	}
+
+	// Links ending on a link are described by the link they end on
+	source.label, target.label = "Client", "Server"
+	other := new(Resource).Init()
+	other.label = "Monitor"
+	junction := new(Link).Init(other, WINDROSE_AUTO, ArrowHead{}, nil, WINDROSE_AUTO, ArrowHead{}, 2, color.RGBA{255, 0, 0, 255})
+	junction.SetTargetLink(link)
+	if text := junction.stepText(); text != "Monitor → (Client → Server)" {
+		t.Errorf("Expected the endpoints of the target link, got %q", text)
+	}
+	link.Labels.AutoRight = &LinkLabel{Title: "Request"}
+	if text := junction.stepText(); text != "Monitor → Request" {
+		t.Errorf("Expected the label of the target link, got %q", text)
+	}
+}
+
+func TestLineStroke(t *testing.T) {
+	tests := []struct {
+		name      string
+		style     string
+		pattern   []float64
+		offsets   []float64
+		visible   []float64
+		invisible []float64
+	}{
+		{"Normal", "normal", nil, []float64{-0.5, 0.5}, []float64{0, 7, 100}, nil},
+		{"Dashed", "dashed", nil, []float64{-0.5, 0.5}, []float64{0, 5, 9}, []float64{6, 8}},
+		{"Dotted", "dotted", nil, []float64{-0.5, 0.5}, []float64{0, 1, 6}, []float64{2, 5}},
+		{"Double", "double", nil, []float64{-2.5, 1.5, -1.5, 2.5}, []float64{0}, nil},
+		{"Custom pattern", "dashed", []float64{8, 4, 2, 4}, []float64{-0.5, 0.5}, []float64{0, 7, 12, 18}, []float64{8, 11, 14, 17}},
+		{"Odd pattern", "normal", []float64{4, 2, 1}, []float64{-0.5, 0.5}, []float64{0, 6, 11, 12}, []float64{4, 7, 8, 13}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := &Link{LineWidth: 2, LineStyle: tt.style}
+			if err := l.SetDashPattern(tt.pattern); err != nil {
+				t.Fatalf("SetDashPattern failed: %v", err)
+			}
+			s := l.lineStroke()
+			if !reflect.DeepEqual(s.offsets, tt.offsets) {
+				t.Errorf("Expected offsets %v, got %v", tt.offsets, s.offsets)
+			}
+			for _, d := range tt.visible {
+				if !s.visible(d) {
+					t.Errorf("Expected visible at %v", d)
+				}
+			}
+			for _, d := range tt.invisible {
+				if s.visible(d) {
+					t.Errorf("Expected invisible at %v", d)
+				}
+			}
+		})
+	}
+
+	// The dashed style restarts at each segment, and custom patterns continue over bends
+	for _, pattern := range [][]float64{nil, {6, 3}} {
+		l := &Link{LineWidth: 1, LineStyle: LINE_STYLE_DASHED, lineColor: color.RGBA{0, 0, 0, 255}}
+		if err := l.SetDashPattern(pattern); err != nil {
+			t.Fatalf("SetDashPattern failed: %v", err)
+		}
+		img := image.NewRGBA(image.Rect(0, 0, 20, 20))
+		l.drawLine(img, image.Point{0, 2}, image.Point{7, 2})
+		l.drawLine(img, image.Point{7, 2}, image.Point{7, 12})
+		if drawn := img.RGBAAt(7, 3).A != 0; drawn != (pattern == nil) {
+			t.Errorf("Expected the start of the second segment drawn=%v with pattern %v", pattern == nil, pattern)
+		}
+	}
+
+	l := &Link{LineWidth: 2}
+	if err := l.SetDashPattern([]float64{4, -2}); err == nil {
+		t.Error("Expected error for negative dash length")
+	}
+	if err := l.SetDashPattern([]float64{0, 0}); err == nil {
+		t.Error("Expected error for dash pattern without length")
+	}
+}
+
+func TestArrowHeadTypes(t *testing.T) {
+	tests := []struct {
+		name  string
+		drawn []image.Point
+		blank []image.Point
+	}{
+		// Arrow pointing east at (50, 50) with the default length and width
+		{"Diamond", []image.Point{{50, 50}, {43, 50}, {37, 50}, {43, 45}}, []image.Point{{43, 40}, {30, 50}}},
+		{"Circle", []image.Point{{50, 50}, {43, 50}, {43, 44}, {37, 50}}, []image.Point{{50, 43}, {30, 50}}},
+		{"CrowsFoot", []image.Point{{43, 47}, {43, 53}, {40, 50}}, []image.Point{{43, 43}, {50, 35}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			img := image.NewRGBA(image.Rect(0, 0, 100, 100))
+			l := &Link{LineWidth: 2, lineColor: color.RGBA{0, 0, 0, 255}, LineStyle: "dotted"}
+			l.drawArrowHead(img, image.Point{50, 50}, image.Point{0, 50}, ArrowHead{Type: tt.name})
+			for _, p := range tt.drawn {
+				if img.RGBAAt(p.X, p.Y).A == 0 {
+					t.Errorf("Expected %v to be drawn", p)
+				}
+			}
+			for _, p := range tt.blank {
+				if img.RGBAAt(p.X, p.Y).A != 0 {
+					t.Errorf("Expected %v to be blank", p)
+				}
+			}
+		})
+	}
+}
+
+func TestNearestBorderPoint(t *testing.T) {
+	border := image.Rect(100, 100, 500, 300)
+	header := image.Rect(100, 100, 200, 164) // Icon and title at the top-left corner
+	tests := []struct {
+		name     string
+		header   image.Rectangle
+		ref      image.Point
+		expected image.Point
+		side     Windrose
+	}{
+		{"Above the border", image.Rectangle{}, image.Point{300, 0}, image.Point{300, 100}, WINDROSE_N},
+		{"Right of the border", image.Rectangle{}, image.Point{600, 250}, image.Point{500, 250}, WINDROSE_E},
+		{"Below a corner", image.Rectangle{}, image.Point{600, 400}, image.Point{500, 290}, WINDROSE_E},
+		{"Slides out of the header", header, image.Point{150, 0}, image.Point{210, 100}, WINDROSE_N},
+		{"Left of the header", header, image.Point{0, 120}, image.Point{100, 174}, WINDROSE_W},
+		{"Beside the header", header, image.Point{300, 0}, image.Point{300, 100}, WINDROSE_N},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pt, side := nearestBorderPoint(border, tt.header, tt.ref)
+			if pt != tt.expected || side != tt.side {
+				t.Errorf("Expected %v on side %v, got %v on side %v", tt.expected, tt.side, pt, side)
+			}
+		})
+	}
+}
+
+func TestResolveNearestPositions(t *testing.T) {
+	if position, err := ConvertLinkPosition("nearest"); err != nil || position != WINDROSE_NEAREST {
+		t.Fatalf("Expected WINDROSE_NEAREST, got %v (%v)", position, err)
+	}
+
+	source := new(Resource).Init()
+	source.SetBindings(image.Rect(300, 0, 364, 64))
+	target := new(Resource).Init()
+	target.SetBindings(image.Rect(100, 200, 500, 400))
+	link := new(Link).Init(source, WINDROSE_NEAREST, ArrowHead{}, target, WINDROSE_NEAREST, ArrowHead{}, 2, color.RGBA{0, 0, 0, 255})
+	if err := link.ResolveAutoPositions(); err != nil {
+		t.Fatalf("ResolveAutoPositions failed: %v", err)
+	}
+	if link.SourcePosition != WINDROSE_S || link.TargetPosition != WINDROSE_N {
+		t.Errorf("Expected S -> N, got %v -> %v", link.SourcePosition, link.TargetPosition)
+	}
+	sourcePt := link.calcPositionWithOffset(source.GetBindings(), link.SourcePosition, source, true)
+	targetPt := link.calcPositionWithOffset(target.GetBindings(), link.TargetPosition, target, false)
+	if sourcePt != (image.Point{332, 64}) || targetPt != (image.Point{332, 200}) {
+		t.Errorf("Expected a straight vertical link (332,64) -> (332,200), got %v -> %v", sourcePt, targetPt)
+	}
+}
+
+func TestNearestPointOnPath(t *testing.T) {
+	path := []image.Point{{0, 0}, {100, 0}, {100, 100}}
+	tests := []struct {
+		name       string
+		pt         image.Point
+		expected   image.Point
+		horizontal bool
+	}{
+		{"Above the horizontal segment", image.Point{40, -50}, image.Point{40, 0}, true},
+		{"Beside the vertical segment", image.Point{150, 60}, image.Point{100, 60}, false},
+		{"Kept away from the start", image.Point{-50, -20}, image.Point{junctionEndMargin, 0}, true},
+		{"Kept away from the end", image.Point{100, 200}, image.Point{100, 100 - junctionEndMargin}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pt, horizontal := nearestPointOnPath(path, tt.pt)
+			if toPoint(pt) != tt.expected || horizontal != tt.horizontal {
+				t.Errorf("Expected %v (horizontal: %v), got %v (horizontal: %v)", tt.expected, tt.horizontal, toPoint(pt), horizontal)
+			}
+		})
+	}
+}
+
+func TestDrawJunction(t *testing.T) {
+	lineColor := color.RGBA{0, 0, 255, 255}
+	bus := new(Link).Init(new(Resource).Init(), WINDROSE_E, ArrowHead{}, new(Resource).Init(), WINDROSE_W, ArrowHead{}, 2, lineColor)
+	bus.path = []image.Point{{0, 200}, {500, 200}}
+
+	source := new(Resource).Init()
+	source.SetBindings(image.Rect(200, 0, 264, 64))
+	link := new(Link).Init(source, WINDROSE_AUTO, ArrowHead{}, nil, WINDROSE_AUTO, ArrowHead{}, 2, lineColor)
+	link.SetTargetLink(bus)
+	if !link.IsJunction() || bus.IsJunction() {
+		t.Fatalf("Expected only the link ending on the bus to be a junction link")
+	}
+
+	img := image.NewRGBA(image.Rect(0, 0, 500, 300))
+	if err := link.DrawJunction(img); err != nil {
+		t.Fatalf("DrawJunction failed: %v", err)
+	}
+	if link.SourcePosition != WINDROSE_S {
+		t.Errorf("Expected the source to face the bus (S), got %v", link.SourcePosition)
+	}
+	junction := link.path[len(link.path)-1]
+	if junction != (image.Point{232, 200}) {
+		t.Errorf("Expected the junction at (232,200), got %v", junction)
+	}
+	// The dot is wider than the line
+	for _, pt := range []image.Point{junction, junction.Add(image.Point{-3, -3}), junction.Add(image.Point{3, 3})} {
+		if c := img.RGBAAt(pt.X, pt.Y); c.B == 0 {
+			t.Errorf("Expected the junction dot at %v, got %v", pt, c)
+		}
+	}
+	if !link.drawn {
+		t.Error("Expected the link to be marked as drawn")
+	}
+}
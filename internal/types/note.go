@@ -0,0 +1,131 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package types
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/math/fixed"
+)
+
+const (
+	noteMaxWidth    = 240 // Default width the text of a note is wrapped to
+	noteFontSize    = 18
+	noteLineSpacing = 4
+	notePadding     = 12 // Space between the frame and the text
+	noteFoldSize    = 16 // Size of the folded corner
+)
+
+var (
+	noteFillColor   = color.RGBA{255, 244, 179, 255}
+	noteBorderColor = color.RGBA{204, 170, 40, 255}
+	noteTextColor   = color.RGBA{51, 51, 51, 255}
+)
+
+// Note is a sticky note with its title drawn as wrapped text in a box with a folded corner
+type Note struct {
+}
+
+func (n Note) Init() *Resource {
+	r := new(Resource).Init()
+	r.note = true
+	r.fillColor = noteFillColor
+	borderColor := noteBorderColor
+	r.borderColor = &borderColor
+	textColor := noteTextColor
+	r.labelColor = &textColor
+	r.fontSize = noteFontSize
+	r.lineSpacing = noteLineSpacing
+	r.maxTitleWidth = noteMaxWidth
+	r.margin = &Margin{20, 20, 20, 20}
+	r.padding = &Padding{0, 0, 0, 0}
+	return r
+}
+
+// IsNote reports whether the resource is a note
+func (r *Resource) IsNote() bool {
+	return r.note
+}
+
+// noteSize returns the size of the box fitting the wrapped text of the note
+func (r *Resource) noteSize(face font.Face) image.Point {
+	lines := wrapText(face, r.label, r.maxTitleWidth)
+	metrics := face.Metrics()
+	lineHeight := (metrics.Ascent + metrics.Descent).Ceil()
+	width := 0
+	for _, line := range lines {
+		width = max(width, font.MeasureString(face, line).Ceil())
+	}
+	return image.Point{
+		width + notePadding*2 + noteFoldSize/2,
+		lineHeight*len(lines) + r.lineSpacing*(len(lines)-1) + notePadding*2,
+	}
+}
+
+// drawNoteFrame draws the box of the note, with its top-right corner folded
+func (r *Resource) drawNoteFrame(img *image.RGBA) {
+	b := *r.bindings
+	fold := min(noteFoldSize, b.Dx()/2, b.Dy()/2)
+	foldX := b.Max.X - 1 - fold
+	foldY := b.Min.Y + fold
+	onBorder := func(x, y int) bool {
+		return x == b.Min.X || x == b.Max.X-1 || y == b.Min.Y || y == b.Max.Y-1
+	}
+	// The flap is the fill color darkened
+	foldColor := color.RGBA{
+		uint8(int(r.fillColor.R) * 85 / 100),
+		uint8(int(r.fillColor.G) * 85 / 100),
+		uint8(int(r.fillColor.B) * 85 / 100),
+		r.fillColor.A,
+	}
+
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			dx, dy := x-foldX, y-b.Min.Y
+			if dx > dy {
+				// Cut corner
+				continue
+			}
+			c := img.At(x, y)
+			border := onBorder(x, y) || dx == dy || (dx >= 0 && dy <= fold && (x == foldX || y == foldY))
+			if r.borderType == BORDER_TYPE_DASHED && (x+y)%9 > 5 {
+				border = false
+			}
+			switch {
+			case border:
+				img.Set(x, y, _blend_color(c, r.borderColor))
+			case dx > 0 && dy < fold:
+				img.Set(x, y, _blend_color(c, foldColor))
+			default:
+				img.Set(x, y, _blend_color(c, r.fillColor))
+			}
+		}
+	}
+}
+
+// drawNoteText draws the wrapped text of the note inside its box
+func (r *Resource) drawNoteText(img *image.RGBA, parent *Resource) error {
+	face, err := r.prepareFontFace(false, parent)
+	if err != nil {
+		return fmt.Errorf("failed to prepare font face for drawing note: %w", err)
+	}
+	metrics := face.Metrics()
+	lineHeight := (metrics.Ascent + metrics.Descent).Ceil()
+	for i, line := range wrapText(face, r.label, r.maxTitleWidth) {
+		d := &font.Drawer{
+			Dst:  img,
+			Src:  image.NewUniform(r.labelColor),
+			Face: face,
+			Dot: fixed.Point26_6{
+				X: fixed.I(r.bindings.Min.X + notePadding),
+				Y: fixed.I(r.bindings.Min.Y+notePadding+(lineHeight+r.lineSpacing)*i) + metrics.Ascent,
+			},
+		}
+		d.DrawString(line)
+	}
+	return nil
+}
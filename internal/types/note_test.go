@@ -0,0 +1,51 @@
+package types
+
+import (
+	"image"
+	"image/color"
+	"testing"
+)
+
+func TestNote(t *testing.T) {
+	canvas := new(Resource).Init()
+	note := new(Note).Init()
+	note.label = "PII stored here. Encrypted with a customer managed KMS key."
+	if err := canvas.AddChild(note); err != nil {
+		t.Fatalf("AddChild failed: %v", err)
+	}
+	if err := canvas.Scale(nil, nil); err != nil {
+		t.Fatalf("Scale failed: %v", err)
+	}
+	b := note.GetBindings()
+	if b.Dx() > noteMaxWidth+notePadding*2+noteFoldSize || b.Dy() <= 60 {
+		t.Errorf("Expected the note text to be wrapped into several lines, got %v", b)
+	}
+
+	// A longer text makes a taller note
+	long := new(Note).Init()
+	long.label = note.label + " Rotated every year."
+	face, err := long.prepareFontFace(false, nil)
+	if err != nil {
+		t.Fatalf("Failed to prepare font face: %v", err)
+	}
+	if long.noteSize(face).Y <= b.Dy() {
+		t.Errorf("Expected a longer text to make a taller note")
+	}
+
+	img := image.NewRGBA(image.Rect(0, 0, b.Max.X+10, b.Max.Y+10))
+	if _, err := canvas.Draw(img, nil); err != nil {
+		t.Fatalf("Draw failed: %v", err)
+	}
+	if c := img.RGBAAt(b.Max.X-2, b.Min.Y+1); c != (color.RGBA{}) {
+		t.Errorf("Expected the top-right corner to be cut, got %v", c)
+	}
+	if c := img.RGBAAt(b.Max.X-4, b.Min.Y+noteFoldSize-2); c == noteFillColor || c == (color.RGBA{}) {
+		t.Errorf("Expected the folded flap to be darker than the note, got %v", c)
+	}
+	if c := img.RGBAAt(b.Min.X+3, b.Max.Y-3); c != noteFillColor {
+		t.Errorf("Expected the note to be filled with %v, got %v", noteFillColor, c)
+	}
+	if c := img.RGBAAt(b.Min.X, b.Max.Y-3); c != noteBorderColor {
+		t.Errorf("Expected the border color %v, got %v", noteBorderColor, c)
+	}
+}
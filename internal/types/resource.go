@@ -8,20 +8,16 @@ import (
 	"fmt"
 	"image"
 	"image/color"
-	"io"
 	"math"
 	"os"
 	"sort"
 	"strconv"
-	"strings"
 
 	fontPath "github.com/awslabs/diagram-as-code/internal/font"
 	"github.com/awslabs/diagram-as-code/internal/vector"
-	"github.com/golang/freetype/truetype"
 	log "github.com/sirupsen/logrus"
 	"golang.org/x/image/draw"
 	"golang.org/x/image/font"
-	"golang.org/x/image/font/gofont/goregular"
 	"golang.org/x/image/math/fixed"
 )
 
@@ -53,12 +49,17 @@ type Resource struct {
 	labelFont               string
 	labelColor              *color.RGBA
 	labelFillColor          *color.RGBA
-	headerAlign             string // left(default) / center / right
+	fontSize                float64 // Size of the title font (0 means the default size)
+	fontWeight              string  // normal / bold
+	lineSpacing             int     // Space between the lines of the title
+	maxTitleWidth           int     // Width the title is wrapped to (0 means no wrapping)
+	headerAlign             string  // left(default) / center / right
 	margin                  *Margin
 	padding                 *Padding
 	direction               string
 	align                   string
 	links                   []*Link
+	junctionLinks           []*Link // Links from this resource ending on another link
 	children                []*Resource
 	borderChildren          []*BorderChild
 	iconfill                ResourceIconFill
@@ -66,8 +67,22 @@ type Resource struct {
 	groupingOffset          bool        // Flag: if true, enable grouping offset for links
 	groupingOffsetDirection bool        // Flag: if true, enable directional grouping offset for links
 	unorderedChildren       bool        // Flag: if true, children order can be rearranged based on links
+	bundling                bool        // Flag: if true, links converging inside this group share a trunk
 	spanTargets             []*Resource // Resources this overlay spans across
 	spanOverlays            []*Resource // Overlay resources that span across this resource
+	pinned                  bool        // Flag: if true, excluded from stack flow and placed by pinAnchor/pinOffset
+	pinAnchor               Windrose    // Anchor on the parent's content area (used only when pinned)
+	pinOffset               image.Point // Offset from the anchor point (used only when pinned)
+	collapsed               bool        // Flag: if true, descendants are hidden and the group is drawn as a single box
+	hiddenCount             int         // Number of leaf resources hidden by Collapse
+	note                    bool        // Flag: if true, drawn as a note with its title inside a folded-corner box
+	badges                  []Badge     // Counters and status dots drawn over the icon
+	stacked                 bool        // Flag: if true, the icon is drawn as a cascaded stack of copies
+	count                   int         // Number of instances shown by the stack (0 means no count label)
+	overhangAdded           bool        // Flag: if true, the margin already keeps room for the stacked icons and badges
+	borderWidth             int         // Width of the border (0 means the default 2px border)
+	cornerRadius            int         // Radius of the rounded corners of the frame
+	shadow                  bool        // Flag: if true, a drop shadow is drawn under the frame
 }
 
 type ResourceIconFill struct {
@@ -130,7 +145,10 @@ func (r *Resource) Init() *Resource {
 	rr.fillColor = color.RGBA{0, 0, 0, 0}
 	rr.label = ""
 	rr.labelFont = ""
-	rr.labelColor = &color.RGBA{0, 0, 0, 255}
+	textColor := defaultTextColor
+	rr.labelColor = &textColor
+	rr.fontWeight = FONT_WEIGHT_NORMAL
+	rr.lineSpacing = defaultLineSpacing
 	rr.headerAlign = "left"
 	rr.margin = nil
 	rr.padding = nil
@@ -223,6 +241,15 @@ func (r *Resource) SetLabel(label *string, labelColor *color.RGBA, labelFont *st
 	}
 }
 
+// GetLabelFont returns the font file of the title, resolved from the parents and the system fonts by Scale
+func (r *Resource) GetLabelFont() string {
+	return r.labelFont
+}
+
+func (r *Resource) GetFillColor() color.RGBA {
+	return r.fillColor
+}
+
 func (r *Resource) SetLabelFillColor(c color.RGBA) {
 	r.labelFillColor = &c
 }
@@ -250,6 +277,21 @@ func (r *Resource) SetGroupingOffsetDirection(enable bool) {
 	r.groupingOffsetDirection = enable
 }
 
+func (r *Resource) SetBundling(enable bool) {
+	r.bundling = enable
+}
+
+// isBundling reports whether links converging inside this resource are bundled,
+// which is enabled on the resource or one of its ancestors
+func (r *Resource) isBundling() bool {
+	for current := r; current != nil; current = current.parent {
+		if current.bundling {
+			return true
+		}
+	}
+	return false
+}
+
 func (r *Resource) SetUnorderedChildren(enable bool) {
 	r.unorderedChildren = enable
 }
@@ -258,6 +300,18 @@ func (r *Resource) GetUnorderedChildren() bool {
 	return r.unorderedChildren
 }
 
+// SetPin excludes the resource from its parent's stack flow and places it
+// at the anchor of the parent's content area, shifted by offset.
+func (r *Resource) SetPin(anchor Windrose, offset image.Point) {
+	r.pinned = true
+	r.pinAnchor = anchor
+	r.pinOffset = offset
+}
+
+func (r *Resource) IsPinned() bool {
+	return r.pinned
+}
+
 func (r *Resource) AddSpanTarget(target *Resource) error {
 	if len(r.children) > 0 {
 		return fmt.Errorf("resource cannot have both Children and SpanResources")
@@ -308,6 +362,15 @@ func (r *Resource) GetLinks() []*Link {
 	return r.links
 }
 
+// AddJunctionLink adds a link from this resource ending on another link
+func (r *Resource) AddJunctionLink(link *Link) {
+	r.junctionLinks = append(r.junctionLinks, link)
+}
+
+func (r *Resource) GetJunctionLinks() []*Link {
+	return r.junctionLinks
+}
+
 func (r *Resource) GetParent() *Resource {
 	return r.parent
 }
@@ -328,6 +391,67 @@ func (r *Resource) AddChild(child *Resource) error {
 	return nil
 }
 
+func (r *Resource) GetChildren() []*Resource {
+	return r.children
+}
+
+// Collapse hides all descendants of the group and appends the number of
+// hidden resources to its title. Hidden resources keep their parent reference
+// so that links can be re-targeted with CollapsedAncestor.
+func (r *Resource) Collapse() {
+	if len(r.children) == 0 && len(r.borderChildren) == 0 {
+		return
+	}
+	count := r.countHiddenResources()
+	r.hiddenCount = count
+	summary := fmt.Sprintf("%d resources", count)
+	if count == 1 {
+		summary = "1 resource"
+	}
+	if r.label != "" {
+		r.label = r.label + "\n" + summary
+	} else {
+		r.label = summary
+	}
+	log.Infof("Collapse %s (%s)", getResourceName(r), summary)
+	r.children = nil
+	r.borderChildren = nil
+	r.collapsed = true
+}
+
+// countHiddenResources counts the leaf resources under the group, including
+// those already hidden by collapsed descendants.
+func (r *Resource) countHiddenResources() int {
+	count := len(r.borderChildren)
+	for _, child := range r.children {
+		switch {
+		case child.collapsed:
+			count += child.hiddenCount
+		case len(child.children) == 0:
+			count += 1 + len(child.borderChildren)
+		default:
+			count += child.countHiddenResources()
+		}
+	}
+	return count
+}
+
+func (r *Resource) IsCollapsed() bool {
+	return r.collapsed
+}
+
+// CollapsedAncestor returns the outermost collapsed ancestor of the resource,
+// or nil if the resource is visible.
+func (r *Resource) CollapsedAncestor() *Resource {
+	var found *Resource
+	for current := r.parent; current != nil; current = current.parent {
+		if current.collapsed {
+			found = current
+		}
+	}
+	return found
+}
+
 func (r *Resource) AddBorderChild(borderChild *BorderChild) error {
 	hasChild := len(borderChild.Resource.children) != 0
 	if hasChild {
@@ -374,48 +498,18 @@ func (r *Resource) prepareFontFace(hasChild bool, parent *Resource) (font.Face,
 		if parent != nil && parent.labelColor != nil {
 			r.labelColor = parent.labelColor
 		} else {
-			r.labelColor = &color.RGBA{0, 0, 0, 255}
-		}
-	}
-	var ttfBytes []byte
-	if r.labelFont == "goregular" || r.labelFont == "" {
-		// Use Go-fonts instead system fonts
-		ttfBytes = goregular.TTF
-	} else {
-		f, err := os.Open(r.labelFont)
-		if err != nil {
-			return nil, fmt.Errorf("failed to open font file: %w", err)
-		}
-		defer func() {
-			if closeErr := f.Close(); closeErr != nil {
-				log.Warnf("Failed to close font file: %v", closeErr)
-			}
-		}()
-
-		ttfBytes, err = io.ReadAll(f)
-		if err != nil {
-			return nil, fmt.Errorf("failed to read font file: %w", err)
+			textColor := defaultTextColor
+			r.labelColor = &textColor
 		}
 	}
-
-	ft, err := truetype.Parse(ttfBytes)
-	if err != nil {
-		return nil, fmt.Errorf("failed to parse font: %w", err)
-	}
-
-	opt := truetype.Options{
-		Size:              24,
-		DPI:               0,
-		Hinting:           0,
-		GlyphCacheEntries: 0,
-		SubPixelsX:        0,
-		SubPixelsY:        0,
-	}
+	size := 24.0
 	if hasChild {
-		opt.Size = 30
+		size = 30
 	}
-
-	return truetype.NewFace(ft, &opt), nil
+	if r.fontSize > 0 {
+		size = r.fontSize
+	}
+	return loadFontFace(r.labelFont, size, r.fontWeight)
 }
 
 func (r *Resource) Scale(parent *Resource, visited map[*Resource]bool) error {
@@ -442,7 +536,7 @@ func (r *Resource) Scale(parent *Resource, visited map[*Resource]bool) error {
 			math.MinInt,
 		},
 	}
-	hasChildren := len(r.children) != 0
+	hasChildren := len(r.children) != 0 || r.collapsed
 	hasBorderChildren := len(r.borderChildren) != 0
 	hasIcon := r.iconImage.Bounds().Max.X != 0
 	log.Infof("hasIcon: %t\n", hasIcon)
@@ -453,6 +547,9 @@ func (r *Resource) Scale(parent *Resource, visited map[*Resource]bool) error {
 		return fmt.Errorf("failed to prepare font face: %w", err)
 	}
 	textWidth, textHeight = r.calculateTitleSize(fontFace)
+	if r.note && r.bindings == nil {
+		r.bindings = &image.Rectangle{Max: r.noteSize(fontFace)}
+	}
 	if r.bindings == nil {
 		r.bindings = defaultResourceValues(hasChildren, hasIcon).bindings
 	}
@@ -488,6 +585,16 @@ func (r *Resource) Scale(parent *Resource, visited map[*Resource]bool) error {
 			r.margin.Left += addMargin.Left
 		}
 	}
+	if (len(r.badges) > 0 || r.stackCopies() > 1) && !r.overhangAdded {
+		// Keep stacked icons and badges sticking out of the icon clear of the neighbours,
+		// also with a margin given by a preset
+		overhang := r.iconOverhang()
+		r.margin.Top += overhang.Top
+		r.margin.Right += overhang.Right
+		r.margin.Bottom += overhang.Bottom
+		r.margin.Left += overhang.Left
+		r.overhangAdded = true
+	}
 	if len(r.spanOverlays) > 0 {
 		for _, overlay := range r.spanOverlays {
 			add := overlay.overlayMarginAddition()
@@ -553,9 +660,15 @@ func (r *Resource) Scale(parent *Resource, visited map[*Resource]bool) error {
 		if r.direction == "vertical" {
 			maxW := 0
 			for _, c := range r.children {
+				if c.pinned {
+					continue
+				}
 				maxW = maxInt(maxW, c.GetBindings().Dx())
 			}
 			for _, c := range r.children {
+				if c.pinned {
+					continue
+				}
 				cb := c.GetBindings()
 				cb.Max.X = cb.Min.X + maxW
 				c.SetBindings(cb)
@@ -563,9 +676,15 @@ func (r *Resource) Scale(parent *Resource, visited map[*Resource]bool) error {
 		} else {
 			maxH := 0
 			for _, c := range r.children {
+				if c.pinned {
+					continue
+				}
 				maxH = maxInt(maxH, c.GetBindings().Dy())
 			}
 			for _, c := range r.children {
+				if c.pinned {
+					continue
+				}
 				cb := c.GetBindings()
 				cb.Max.Y = cb.Min.Y + maxH
 				c.SetBindings(cb)
@@ -580,6 +699,10 @@ func (r *Resource) Scale(parent *Resource, visited map[*Resource]bool) error {
 				return err
 			}
 		}
+		// Pinned children are placed by ResolvePinnedPositions and take no space in the flow
+		if subResource.pinned {
+			continue
+		}
 
 		bindings := subResource.GetBindings()
 		margin := subResource.GetMargin()
@@ -651,6 +774,14 @@ func (r *Resource) Scale(parent *Resource, visited map[*Resource]bool) error {
 		b.Max.Y = maxInt(b.Max.Y, bindings.Max.Y+margin.Bottom+r.padding.Bottom)
 		prev = subResource
 	}
+	if r.collapsed {
+		// Collapsed group: only the header (icon and title) is drawn inside the frame
+		headerHeight := maxInt(r.iconBounds.Dy(), textHeight)
+		if r.headerAlign == "center" {
+			headerHeight = r.iconBounds.Dy() + textHeight
+		}
+		b = image.Rect(0, 0, textWidth+r.iconBounds.Dx()+30, headerHeight+r.padding.Top+r.padding.Bottom)
+	}
 	// Expand bindings to fit text size
 	if hasChildren && r.direction == "horizontal" {
 		// Group (has child)
@@ -693,6 +824,9 @@ func (r *Resource) propagateExpand() {
 	if r.align == "expand" && len(r.children) > 0 {
 		if r.direction == "vertical" {
 			for _, c := range r.children {
+				if c.pinned {
+					continue
+				}
 				m := c.GetMargin()
 				maxW := r.bindings.Dx() - r.padding.Left - r.padding.Right - m.Left - m.Right
 				cb := c.GetBindings()
@@ -703,6 +837,9 @@ func (r *Resource) propagateExpand() {
 			}
 		} else {
 			for _, c := range r.children {
+				if c.pinned {
+					continue
+				}
 				m := c.GetMargin()
 				maxH := r.bindings.Dy() - r.padding.Top - r.padding.Bottom - m.Top - m.Bottom
 				cb := c.GetBindings()
@@ -749,6 +886,48 @@ func (r *Resource) ZeroAdjust() error {
 	return r.Translation(-r.bindings.Min.X+r.padding.Left, -r.bindings.Min.Y+r.padding.Top)
 }
 
+// ResolvePinnedPositions places pinned children relative to their parent's
+// content area. It must be called after ZeroAdjust so that the parent's
+// bindings are final. Parents are resolved before their descendants.
+func (r *Resource) ResolvePinnedPositions() error {
+	for _, child := range r.children {
+		if child.pinned {
+			if r.bindings == nil || child.bindings == nil {
+				return fmt.Errorf("cannot place pinned resource %s: resource is not scaled", getResourceName(child))
+			}
+			padding := r.GetPadding()
+			content := image.Rect(
+				r.bindings.Min.X+padding.Left, r.bindings.Min.Y+padding.Top,
+				r.bindings.Max.X-padding.Right, r.bindings.Max.Y-padding.Bottom,
+			)
+			m := child.GetMargin()
+			box := image.Rect(
+				child.bindings.Min.X-m.Left, child.bindings.Min.Y-m.Top,
+				child.bindings.Max.X+m.Right, child.bindings.Max.Y+m.Bottom,
+			)
+			anchorPt, err := calcPosition(content, child.pinAnchor)
+			if err != nil {
+				return fmt.Errorf("failed to calculate anchor for pinned resource %s: %w", getResourceName(child), err)
+			}
+			childPt, err := calcPosition(box, child.pinAnchor)
+			if err != nil {
+				return fmt.Errorf("failed to calculate anchor for pinned resource %s: %w", getResourceName(child), err)
+			}
+			log.Infof("Pin %s at %v (anchor %v, offset %v)", getResourceName(child), anchorPt, child.pinAnchor, child.pinOffset)
+			if err := child.Translation(
+				anchorPt.X-childPt.X+child.pinOffset.X,
+				anchorPt.Y-childPt.Y+child.pinOffset.Y,
+			); err != nil {
+				return fmt.Errorf("failed to translate pinned resource: %w", err)
+			}
+		}
+		if err := child.ResolvePinnedPositions(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func (r *Resource) IsDrawn() bool {
 	return r.drawn
 }
@@ -758,12 +937,15 @@ func (r *Resource) calculateTitleSize(fontFace font.Face) (textWidth, textHeight
 		return 0, 0
 	}
 
-	textHeight = 10
-	texts := strings.Split(r.label, "\n")
-	for _, line := range texts {
+	textHeight = 20
+	texts := wrapText(fontFace, r.label, r.maxTitleWidth)
+	for i, line := range texts {
+		if i > 0 {
+			textHeight += r.lineSpacing
+		}
 		textBindings, _ := font.BoundString(fontFace, line)
 		textWidth = max(textWidth, textBindings.Max.X.Floor()-textBindings.Min.X.Ceil()+20)
-		textHeight += textBindings.Max.Y.Floor() - textBindings.Min.Y.Ceil() + 10
+		textHeight += textBindings.Max.Y.Floor() - textBindings.Min.Y.Ceil()
 	}
 	return textWidth, textHeight
 }
@@ -784,7 +966,7 @@ func (r *Resource) Draw(img *image.RGBA, parent *Resource) (*image.RGBA, error)
 	r.drawIcon(img)
 	hasIcon := r.iconImage.Bounds().Max.X != 0
 	if parent != nil {
-		if err := r.drawLabel(img, parent, len(r.children) > 0, hasIcon); err != nil {
+		if err := r.drawLabel(img, parent, len(r.children) > 0 || r.collapsed, hasIcon); err != nil {
 			return nil, fmt.Errorf("failed to draw label: %w", err)
 		}
 	}
@@ -1040,16 +1222,8 @@ func getDirectionVectorStatic(position int) vector.Vector {
 
 func (r *Resource) drawIcon(img *image.RGBA) {
 	rctSrc := r.iconImage.Bounds()
-	x := image.Rectangle{r.bindings.Min, r.bindings.Min.Add(image.Point{64, 64})}
-	switch r.headerAlign {
-	case "left":
-	case "center":
-		x.Min = x.Min.Add(image.Point{(r.bindings.Dx() - 64) / 2, 0})
-		x.Max = x.Max.Add(image.Point{(r.bindings.Dx() - 64) / 2, 0})
-	case "right":
-		x.Min = x.Min.Add(image.Point{r.bindings.Dx() - 64, 0})
-		x.Max = x.Max.Add(image.Point{r.bindings.Dx() - 64, 0})
-	}
+	x := r.iconRect()
+	r.drawStackedCopies(img)
 	if r.iconfill.Type == ICON_FILL_TYPE_RECT {
 		for _x := x.Min.X; _x < x.Max.X; _x++ {
 			for _y := x.Min.Y; _y < x.Max.Y; _y++ {
@@ -1059,9 +1233,18 @@ func (r *Resource) drawIcon(img *image.RGBA) {
 		}
 	}
 	draw.CatmullRom.Scale(img, x, r.iconImage, rctSrc, draw.Over, nil)
+	r.drawBadges(img)
 }
 
 func (r *Resource) drawFrame(img *image.RGBA) {
+	if r.note {
+		r.drawNoteFrame(img)
+		return
+	}
+	if r.hasStyledFrame() {
+		r.drawStyledFrame(img)
+		return
+	}
 	x1 := r.bindings.Min.X
 	x2 := r.bindings.Max.X
 	y1 := r.bindings.Min.Y
@@ -1131,13 +1314,18 @@ func (r *Resource) drawMargin(img *image.RGBA) {
 }
 
 func (r *Resource) drawLabel(img *image.RGBA, parent *Resource, hasChild, hasIcon bool) error {
+	if r.note {
+		return r.drawNoteText(img, parent)
+	}
 	face, err := r.prepareFontFace(hasChild, parent)
 	if err != nil {
 		return fmt.Errorf("failed to prepare font face for drawing label: %w", err)
 	}
 
-	texts := strings.Split(r.label, "\n")
+	texts := wrapText(face, r.label, r.maxTitleWidth)
 	lineOffset := 0
+	// Keep the title below the badges on the bottom of the icon
+	badgeOffset := r.iconOverhang().Bottom
 
 	for _, line := range texts {
 		textBindings, _ := font.BoundString(face, line)
@@ -1150,7 +1338,7 @@ func (r *Resource) drawLabel(img *image.RGBA, parent *Resource, hasChild, hasIco
 
 		p := r.bindings.Min.Add(image.Point{0, r.iconBounds.Max.Y})
 
-		point := fixed.Point26_6{fixed.I(p.X) - (w-fixed.I(r.bindings.Dx()))/2, fixed.I(p.Y+10) + h}
+		point := fixed.Point26_6{fixed.I(p.X) - (w-fixed.I(r.bindings.Dx()))/2, fixed.I(p.Y+10+badgeOffset) + h}
 		if hasChild {
 			iconHeight := r.iconBounds.Max.Y
 			if iconHeight == 0 {
@@ -1200,7 +1388,7 @@ func (r *Resource) drawLabel(img *image.RGBA, parent *Resource, hasChild, hasIco
 			Dot:  point,
 		}
 		d.DrawString(line)
-		lineOffset += textHeight + 10
+		lineOffset += textHeight + r.lineSpacing
 	}
 	return nil
 }
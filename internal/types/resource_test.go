@@ -850,6 +850,47 @@ func TestCalculateTitleSize(t *testing.T) {
 	}
 }
 
+func TestCalculateTitleSize_Wrapped(t *testing.T) {
+	resource := new(Resource).Init()
+	resource.label = "Application Load Balancer for the web tier"
+	fontFace, err := resource.prepareFontFace(false, nil)
+	if err != nil {
+		t.Fatalf("Failed to prepare font face: %v", err)
+	}
+	width, height := resource.calculateTitleSize(fontFace)
+
+	resource.SetMaxTitleWidth(150)
+	wrappedWidth, wrappedHeight := resource.calculateTitleSize(fontFace)
+	if wrappedWidth > 150 || wrappedWidth >= width {
+		t.Errorf("Expected wrapped width within 150 and below %d, got %d", width, wrappedWidth)
+	}
+	if wrappedHeight <= height {
+		t.Errorf("Expected wrapped height above %d, got %d", height, wrappedHeight)
+	}
+
+	resource.SetLineSpacing(30)
+	_, spacedHeight := resource.calculateTitleSize(fontFace)
+	if spacedHeight <= wrappedHeight {
+		t.Errorf("Expected larger line spacing to increase height above %d, got %d", wrappedHeight, spacedHeight)
+	}
+
+	resource.SetFontSize(12)
+	smallFace, err := resource.prepareFontFace(false, nil)
+	if err != nil {
+		t.Fatalf("Failed to prepare font face: %v", err)
+	}
+	if smallFace.Metrics().Height >= fontFace.Metrics().Height {
+		t.Errorf("Expected FontSize 12 to be smaller than the default size")
+	}
+
+	if err := resource.SetFontWeight("heavy"); err == nil {
+		t.Errorf("Expected error for unknown font weight")
+	}
+	if err := resource.SetFontWeight("bold"); err != nil {
+		t.Errorf("SetFontWeight failed: %v", err)
+	}
+}
+
 func TestExpandAlign(t *testing.T) {
 	t.Run("VerticalExpandEqualizesWidth", func(t *testing.T) {
 		parent := new(Resource).Init()
@@ -981,3 +1022,148 @@ func TestExpandAlign(t *testing.T) {
 		}
 	})
 }
+
+func TestResolvePinnedPositions(t *testing.T) {
+	newChild := func() *Resource {
+		c := new(Resource).Init()
+		c.SetBindings(image.Rect(0, 0, 64, 64))
+		c.SetMargin(Margin{10, 10, 10, 10})
+		c.SetPadding(Padding{0, 0, 0, 0})
+		return c
+	}
+
+	t.Run("PinnedChildIsExcludedFromFlow", func(t *testing.T) {
+		reference := new(Resource).Init()
+		if err := reference.AddChild(newChild()); err != nil {
+			t.Fatalf("AddChild failed: %v", err)
+		}
+		if err := reference.Scale(nil, nil); err != nil {
+			t.Fatalf("Scale failed: %v", err)
+		}
+
+		parent := new(Resource).Init()
+		if err := parent.AddChild(newChild()); err != nil {
+			t.Fatalf("AddChild failed: %v", err)
+		}
+		pinned := newChild()
+		pinned.SetPin(WINDROSE_NE, image.Point{})
+		if err := parent.AddChild(pinned); err != nil {
+			t.Fatalf("AddChild failed: %v", err)
+		}
+		if err := parent.Scale(nil, nil); err != nil {
+			t.Fatalf("Scale failed: %v", err)
+		}
+
+		if parent.GetBindings().Dx() != reference.GetBindings().Dx() {
+			t.Errorf("Expected pinned child to take no space: width %d, want %d",
+				parent.GetBindings().Dx(), reference.GetBindings().Dx())
+		}
+	})
+
+	t.Run("AnchorNE", func(t *testing.T) {
+		parent := new(Resource).Init()
+		if err := parent.AddChild(newChild()); err != nil {
+			t.Fatalf("AddChild failed: %v", err)
+		}
+		pinned := newChild()
+		pinned.SetPin(WINDROSE_NE, image.Point{})
+		if err := parent.AddChild(pinned); err != nil {
+			t.Fatalf("AddChild failed: %v", err)
+		}
+		if err := parent.Scale(nil, nil); err != nil {
+			t.Fatalf("Scale failed: %v", err)
+		}
+		if err := parent.ZeroAdjust(); err != nil {
+			t.Fatalf("ZeroAdjust failed: %v", err)
+		}
+		if err := parent.ResolvePinnedPositions(); err != nil {
+			t.Fatalf("ResolvePinnedPositions failed: %v", err)
+		}
+
+		pb := parent.GetBindings()
+		p := parent.GetPadding()
+		cb := pinned.GetBindings()
+		if cb.Max.X != pb.Max.X-p.Right-10 || cb.Min.Y != pb.Min.Y+p.Top+10 {
+			t.Errorf("Expected pinned child at top-right of content area, got %v in %v", cb, pb)
+		}
+	})
+
+	t.Run("PositionOffset", func(t *testing.T) {
+		parent := new(Resource).Init()
+		if err := parent.AddChild(newChild()); err != nil {
+			t.Fatalf("AddChild failed: %v", err)
+		}
+		pinned := newChild()
+		pinned.SetPin(WINDROSE_NW, image.Point{X: 5, Y: 7})
+		if err := parent.AddChild(pinned); err != nil {
+			t.Fatalf("AddChild failed: %v", err)
+		}
+		if err := parent.Scale(nil, nil); err != nil {
+			t.Fatalf("Scale failed: %v", err)
+		}
+		if err := parent.ZeroAdjust(); err != nil {
+			t.Fatalf("ZeroAdjust failed: %v", err)
+		}
+		if err := parent.ResolvePinnedPositions(); err != nil {
+			t.Fatalf("ResolvePinnedPositions failed: %v", err)
+		}
+
+		pb := parent.GetBindings()
+		p := parent.GetPadding()
+		want := image.Point{X: pb.Min.X + p.Left + 10 + 5, Y: pb.Min.Y + p.Top + 10 + 7}
+		if pinned.GetBindings().Min != want {
+			t.Errorf("Expected pinned child at %v, got %v", want, pinned.GetBindings().Min)
+		}
+	})
+}
+
+func TestCollapse(t *testing.T) {
+	group := new(Resource).Init()
+	title := "Data Platform"
+	group.SetLabel(&title, nil, nil)
+	inner := new(Resource).Init()
+	leaf1 := new(Resource).Init()
+	leaf2 := new(Resource).Init()
+	leaf3 := new(Resource).Init()
+	if err := inner.AddChild(leaf1); err != nil {
+		t.Fatalf("AddChild failed: %v", err)
+	}
+	if err := inner.AddChild(leaf2); err != nil {
+		t.Fatalf("AddChild failed: %v", err)
+	}
+	if err := group.AddChild(inner); err != nil {
+		t.Fatalf("AddChild failed: %v", err)
+	}
+	if err := group.AddChild(leaf3); err != nil {
+		t.Fatalf("AddChild failed: %v", err)
+	}
+
+	// Collapse inner first to check that hidden resources are still counted
+	inner.Collapse()
+	group.Collapse()
+
+	if !group.IsCollapsed() {
+		t.Fatal("Expected group to be collapsed")
+	}
+	if len(group.GetChildren()) != 0 {
+		t.Errorf("Expected no visible children, got %d", len(group.GetChildren()))
+	}
+	if group.label != "Data Platform\n3 resources" {
+		t.Errorf("Expected aggregated label, got %q", group.label)
+	}
+	if leaf1.CollapsedAncestor() != group {
+		t.Error("Expected outermost collapsed ancestor to be group")
+	}
+	if group.CollapsedAncestor() != nil {
+		t.Error("Expected collapsed group itself to be visible")
+	}
+
+	if err := group.Scale(nil, nil); err != nil {
+		t.Fatalf("Scale failed: %v", err)
+	}
+	b := group.GetBindings()
+	p := group.GetPadding()
+	if b.Dx() <= 0 || b.Dy() <= p.Top+p.Bottom {
+		t.Errorf("Expected collapsed group to be drawn as a box with a header, got %v", b)
+	}
+}
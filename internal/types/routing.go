@@ -0,0 +1,274 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package types
+
+import (
+	"container/heap"
+	"image"
+	"sort"
+
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	routingObstacleMargin = 8  // Clearance kept between a routed link and a resource
+	routingStubLength     = 20 // Length of the first/last segment leaving the source/target
+	routingBendPenalty    = 64 // Cost of a bend, in pixels of path length
+)
+
+// routingDirections are the unit moves on the visibility grid (N, E, S, W)
+var routingDirections = [4]image.Point{{0, -1}, {1, 0}, {0, 1}, {-1, 0}}
+
+// calculateRoutedPath finds a minimum-bend orthogonal path between sourcePt and targetPt
+// that avoids all resources except the source and target. It returns the control points
+// (excluding sourcePt and targetPt) and false when no path is found.
+func (l *Link) calculateRoutedPath(sourcePt, targetPt image.Point) ([]image.Point, bool) {
+	obstacles := collectRoutingObstacles(l.Source, l.Target)
+	log.Infof("Routing from %v to %v around %d obstacle(s)", sourcePt, targetPt, len(obstacles))
+	sourceDir := windroseToRoutingDirection(l.SourcePosition)
+	targetDir := windroseToRoutingDirection(l.TargetPosition)
+
+	d := routingDirections[sourceDir]
+	start := image.Point{sourcePt.X + d.X*routingStubLength, sourcePt.Y + d.Y*routingStubLength}
+	d = routingDirections[targetDir]
+	goal := image.Point{targetPt.X + d.X*routingStubLength, targetPt.Y + d.Y*routingStubLength}
+
+	// The last segment enters the target opposite to its position direction
+	path, ok := routeOrthogonal(start, goal, sourceDir, (targetDir+2)%4, obstacles)
+	if !ok {
+		log.Infof("No obstacle-free route found from %v to %v", sourcePt, targetPt)
+		return nil, false
+	}
+	return simplifyOrthogonalPath(append(append([]image.Point{sourcePt}, path...), targetPt)), true
+}
+
+// windroseToRoutingDirection converts a windrose position to an index of routingDirections
+func windroseToRoutingDirection(position Windrose) int {
+	return ((int(position) + 2) % 16) / 4
+}
+
+// collectRoutingObstacles returns the bindings of every resource without children on the
+// same canvas as source and target, inflated by routingObstacleMargin.
+// Groups are not obstacles since links are allowed to cross group borders.
+func collectRoutingObstacles(source, target *Resource) []image.Rectangle {
+	root := source
+	for root.parent != nil {
+		root = root.parent
+	}
+
+	obstacles := []image.Rectangle{}
+	var walk func(r *Resource)
+	walk = func(r *Resource) {
+		if len(r.children) == 0 && r != source && r != target && r.bindings != nil {
+			if o := routingObstacle(r); !o.Empty() {
+				obstacles = append(obstacles, o.Inset(-routingObstacleMargin))
+			}
+		}
+		for _, child := range r.children {
+			walk(child)
+		}
+		for _, bc := range r.borderChildren {
+			walk(bc.Resource)
+		}
+	}
+	walk(root)
+	return obstacles
+}
+
+// routingObstacle returns the area covered by a resource and its title, which is drawn
+// under the icon and may extend beyond the bindings
+func routingObstacle(r *Resource) image.Rectangle {
+	b := *r.bindings
+	if r.label == "" || r.note {
+		return b
+	}
+	face, err := r.prepareFontFace(false, r.parent)
+	if err != nil {
+		return b
+	}
+	w, h := r.calculateTitleSize(face)
+	top := b.Min.Y + r.iconBounds.Max.Y
+	center := (b.Min.X + b.Max.X) / 2
+	return b.Union(image.Rect(center-w/2, top, center+w/2, top+h))
+}
+
+// routeOrthogonal runs A* on the sparse visibility grid built from the obstacle edges.
+// The cost of a path is its length plus routingBendPenalty per bend, including a bend
+// when leaving start in a direction other than startDir or arriving at goal in a
+// direction other than goalDir. The returned path includes start and goal.
+func routeOrthogonal(start, goal image.Point, startDir, goalDir int, obstacles []image.Rectangle) ([]image.Point, bool) {
+	xs := []int{start.X, goal.X}
+	ys := []int{start.Y, goal.Y}
+	for _, o := range obstacles {
+		xs = append(xs, o.Min.X, o.Max.X)
+		ys = append(ys, o.Min.Y, o.Max.Y)
+	}
+	xs = uniqueSortedInts(xs)
+	ys = uniqueSortedInts(ys)
+
+	xIndex := make(map[int]int, len(xs))
+	for i, x := range xs {
+		xIndex[x] = i
+	}
+	yIndex := make(map[int]int, len(ys))
+	for i, y := range ys {
+		yIndex[y] = i
+	}
+
+	blocked := func(p image.Point) bool {
+		if p == start || p == goal {
+			return false
+		}
+		for _, o := range obstacles {
+			if p.X > o.Min.X && p.X < o.Max.X && p.Y > o.Min.Y && p.Y < o.Max.Y {
+				return true
+			}
+		}
+		return false
+	}
+	crosses := func(a, b image.Point) bool {
+		for _, o := range obstacles {
+			if segmentCrossesRect(a, b, o) {
+				return true
+			}
+		}
+		return false
+	}
+	point := func(n routingNode) image.Point {
+		return image.Point{xs[n.xi], ys[n.yi]}
+	}
+	heuristic := func(p image.Point) int {
+		return abs(p.X-goal.X) + abs(p.Y-goal.Y)
+	}
+
+	startNode := routingNode{xIndex[start.X], yIndex[start.Y], startDir}
+	cost := map[routingNode]int{startNode: 0}
+	prev := make(map[routingNode]routingNode)
+	queue := &routingQueue{}
+	heap.Push(queue, routingItem{node: startNode, priority: heuristic(start)})
+
+	for queue.Len() > 0 {
+		item := heap.Pop(queue).(routingItem)
+		current := item.node
+		currentPt := point(current)
+		if item.cost > cost[current] {
+			continue
+		}
+		if currentPt == goal && current.dir == goalDir {
+			path := []image.Point{currentPt}
+			for current != startNode {
+				current = prev[current]
+				path = append([]image.Point{point(current)}, path...)
+			}
+			return path, true
+		}
+		if currentPt == goal {
+			// Turn at the goal to enter the target from the right side
+			next := routingNode{current.xi, current.yi, goalDir}
+			nextCost := item.cost + routingBendPenalty
+			if c, ok := cost[next]; !ok || nextCost < c {
+				cost[next] = nextCost
+				prev[next] = current
+				heap.Push(queue, routingItem{node: next, cost: nextCost, priority: nextCost})
+			}
+		}
+		for dir, d := range routingDirections {
+			if dir == (current.dir+2)%4 {
+				continue // No U-turns
+			}
+			next := routingNode{current.xi + d.X, current.yi + d.Y, dir}
+			if next.xi < 0 || next.xi >= len(xs) || next.yi < 0 || next.yi >= len(ys) {
+				continue
+			}
+			nextPt := point(next)
+			if blocked(nextPt) || crosses(currentPt, nextPt) {
+				continue
+			}
+			nextCost := item.cost + abs(nextPt.X-currentPt.X) + abs(nextPt.Y-currentPt.Y)
+			if dir != current.dir {
+				nextCost += routingBendPenalty
+			}
+			if c, ok := cost[next]; ok && nextCost >= c {
+				continue
+			}
+			cost[next] = nextCost
+			prev[next] = current
+			heap.Push(queue, routingItem{node: next, cost: nextCost, priority: nextCost + heuristic(nextPt)})
+		}
+	}
+	return nil, false
+}
+
+// segmentCrossesRect reports whether an axis-aligned segment passes through the interior of r
+func segmentCrossesRect(a, b image.Point, r image.Rectangle) bool {
+	if a.Y == b.Y {
+		if a.Y <= r.Min.Y || a.Y >= r.Max.Y {
+			return false
+		}
+		return min(a.X, b.X) < r.Max.X && max(a.X, b.X) > r.Min.X
+	}
+	if a.X <= r.Min.X || a.X >= r.Max.X {
+		return false
+	}
+	return min(a.Y, b.Y) < r.Max.Y && max(a.Y, b.Y) > r.Min.Y
+}
+
+// simplifyOrthogonalPath removes duplicated and collinear points and returns the
+// control points between the first and last point
+func simplifyOrthogonalPath(path []image.Point) []image.Point {
+	pts := []image.Point{}
+	for _, p := range path {
+		if len(pts) > 0 && pts[len(pts)-1] == p {
+			continue
+		}
+		if len(pts) >= 2 {
+			a, b := pts[len(pts)-2], pts[len(pts)-1]
+			if (a.X == b.X && b.X == p.X) || (a.Y == b.Y && b.Y == p.Y) {
+				pts[len(pts)-1] = p
+				continue
+			}
+		}
+		pts = append(pts, p)
+	}
+	if len(pts) <= 2 {
+		return []image.Point{}
+	}
+	return pts[1 : len(pts)-1]
+}
+
+func uniqueSortedInts(values []int) []int {
+	sort.Ints(values)
+	result := []int{}
+	for i, v := range values {
+		if i == 0 || v != values[i-1] {
+			result = append(result, v)
+		}
+	}
+	return result
+}
+
+// routingNode is a point on the visibility grid with the direction it was entered from
+type routingNode struct {
+	xi, yi, dir int
+}
+
+type routingItem struct {
+	node     routingNode
+	cost     int
+	priority int
+}
+
+// routingQueue is a min-heap of routingItem ordered by priority
+type routingQueue []routingItem
+
+func (q routingQueue) Len() int           { return len(q) }
+func (q routingQueue) Less(i, j int) bool { return q[i].priority < q[j].priority }
+func (q routingQueue) Swap(i, j int)      { q[i], q[j] = q[j], q[i] }
+func (q *routingQueue) Push(x any)        { *q = append(*q, x.(routingItem)) }
+func (q *routingQueue) Pop() any {
+	old := *q
+	item := old[len(old)-1]
+	*q = old[:len(old)-1]
+	return item
+}
@@ -0,0 +1,86 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package types
+
+import (
+	"fmt"
+	"image"
+
+	"golang.org/x/image/draw"
+)
+
+const (
+	stackedCopies = 3 // Number of icons drawn for a stacked resource, including the front icon
+	stackedOffset = 8 // Offset of each copy behind the front icon, upwards and to the right
+)
+
+// SetStacked draws the icon as a cascaded stack of copies, the notation for multiple instances
+func (r *Resource) SetStacked(stacked bool) {
+	r.stacked = stacked
+}
+
+// SetCount draws the icon as a stack with a count label such as "×5".
+// A count of 1 draws a single icon without label.
+func (r *Resource) SetCount(count int) error {
+	if count < 1 {
+		return fmt.Errorf("count must be at least 1, got %d", count)
+	}
+	r.count = count
+	return nil
+}
+
+func (r *Resource) GetCount() int {
+	return r.count
+}
+
+// stackCopies returns the number of icons drawn, including the front icon
+func (r *Resource) stackCopies() int {
+	if r.iconImage.Bounds().Dx() == 0 {
+		return 1
+	}
+	if r.count > 1 {
+		return min(r.count, stackedCopies)
+	}
+	if r.stacked && r.count == 0 {
+		return stackedCopies
+	}
+	return 1
+}
+
+// stackRect returns the rectangle covering the front icon and its copies
+func (r *Resource) stackRect() image.Rectangle {
+	front := r.badgeAnchor()
+	k := r.stackCopies() - 1
+	return front.Union(front.Add(image.Point{k * stackedOffset, -k * stackedOffset}))
+}
+
+// countBadge returns the count label drawn on the top-right corner of the stack
+func (r *Resource) countBadge() (Badge, bool) {
+	if r.count <= 1 {
+		return Badge{}, false
+	}
+	return Badge{Text: fmt.Sprintf("×%d", r.count), Color: defaultTextColor, Position: WINDROSE_NE}, true
+}
+
+// drawStackedCopies draws the copies of the icon behind the front icon, from the back.
+// The area of each copy in front of another is filled with the color under the icon, with a
+// 1px gap, so that the copies stay apart and icons with transparent backgrounds hide the copies behind them.
+func (r *Resource) drawStackedCopies(img *image.RGBA) {
+	copies := r.stackCopies()
+	if copies <= 1 {
+		return
+	}
+	front := r.iconRect()
+	background := img.At((front.Min.X+front.Max.X)/2, (front.Min.Y+front.Max.Y)/2)
+	src := image.NewUniform(background)
+	for k := copies - 1; k >= 0; k-- {
+		rect := front.Add(image.Point{k * stackedOffset, -k * stackedOffset})
+		if k < copies-1 {
+			draw.Draw(img, rect.Inset(-1), src, image.Point{}, draw.Src)
+		}
+		if k > 0 {
+			draw.CatmullRom.Scale(img, rect, r.iconImage, r.iconImage.Bounds(), draw.Over, nil)
+		}
+	}
+}
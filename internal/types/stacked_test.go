@@ -0,0 +1,68 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package types
+
+import (
+	"image"
+	"testing"
+)
+
+func TestStackedIcon(t *testing.T) {
+	newResource := func(stacked bool, count int) *Resource {
+		r := new(Resource).Init()
+		r.iconImage = image.NewRGBA(image.Rect(0, 0, 64, 64))
+		r.iconBounds = image.Rect(0, 0, 64, 64)
+		r.bindings = &image.Rectangle{image.Point{100, 100}, image.Point{164, 164}}
+		r.SetStacked(stacked)
+		if count != 0 {
+			if err := r.SetCount(count); err != nil {
+				t.Fatalf("SetCount failed: %v", err)
+			}
+		}
+		return r
+	}
+
+	tests := []struct {
+		name    string
+		stacked bool
+		count   int
+		copies  int
+		label   string
+	}{
+		{"Single", false, 0, 1, ""},
+		{"Stacked", true, 0, stackedCopies, ""},
+		{"CountOfTwo", false, 2, 2, "×2"},
+		{"Count", false, 12, stackedCopies, "×12"},
+		{"CountOfOne", true, 1, 1, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := newResource(tt.stacked, tt.count)
+			if copies := r.stackCopies(); copies != tt.copies {
+				t.Errorf("Expected %d copies, got %d", tt.copies, copies)
+			}
+			badge, ok := r.countBadge()
+			if ok != (tt.label != "") || badge.Text != tt.label {
+				t.Errorf("Expected count label %q, got %q", tt.label, badge.Text)
+			}
+		})
+	}
+
+	r := newResource(true, 0)
+	offset := (stackedCopies - 1) * stackedOffset
+	expected := image.Rect(100, 100-offset, 164+offset, 164)
+	if rect := r.stackRect(); rect != expected {
+		t.Errorf("Expected the stack to cover %v, got %v", expected, rect)
+	}
+	if m := r.iconOverhang(); m.Top != offset || m.Right != offset || m.Bottom != 0 || m.Left != 0 {
+		t.Errorf("Expected the copies to stick out upwards and to the right, got %+v", m)
+	}
+	// Links attach to the front icon
+	if r.GetBindings() != image.Rect(100, 100, 164, 164) {
+		t.Errorf("Expected the bindings to stay on the front icon, got %v", r.GetBindings())
+	}
+	if err := r.SetCount(0); err == nil {
+		t.Error("Expected an error for a count below 1")
+	}
+}
@@ -0,0 +1,242 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package types
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"math"
+	"sort"
+	"strconv"
+
+	"github.com/awslabs/diagram-as-code/internal/vector"
+	"golang.org/x/image/font"
+	"golang.org/x/image/math/fixed"
+)
+
+const (
+	STEP_POSITION_MIDDLE = "middle"
+	STEP_POSITION_SOURCE = "source"
+)
+
+const (
+	stepBadgeRadius    = 12.0 // Minimum radius of a step badge
+	stepBadgeFontSize  = 16.0
+	stepLegendFontSize = 20.0
+	stepLegendPadding  = 12 // Space between the legend frame and its entries
+	stepLegendMargin   = 20 // Space between the diagram and the legend
+)
+
+// SetStep sets the sequence number drawn on the link, and where it is drawn (middle or source)
+func (l *Link) SetStep(step int, position string) error {
+	switch position {
+	case "", STEP_POSITION_MIDDLE:
+		position = STEP_POSITION_MIDDLE
+	case STEP_POSITION_SOURCE:
+	default:
+		return fmt.Errorf("unknown step position %s (allowed: middle, source)", position)
+	}
+	l.Step = step
+	l.stepPosition = position
+	return nil
+}
+
+// stepBadgeFace returns the bold face of the step numbers in the font file of the diagram
+func stepBadgeFace(fontFile string) (font.Face, error) {
+	return loadFontFace(fontFile, stepBadgeFontSize, FONT_WEIGHT_BOLD)
+}
+
+// stepBadgeRadiusFor returns the radius of the badge of a step number: stepBadgeRadius,
+// grown for numbers with several digits
+func stepBadgeRadiusFor(face font.Face, text string) float64 {
+	return math.Max(stepBadgeRadius, float64(font.MeasureString(face, text).Ceil())/2+5)
+}
+
+// pointAlongPath returns the point at the given distance from the start of a polyline
+func pointAlongPath(path []image.Point, distance float64) vector.Vector {
+	for i := 0; i < len(path)-1; i++ {
+		a := vector.New(float64(path[i].X), float64(path[i].Y))
+		d := vector.New(float64(path[i+1].X-path[i].X), float64(path[i+1].Y-path[i].Y))
+		if d.Length() >= distance {
+			if d.IsZero() {
+				return a
+			}
+			return a.Add(d.Normalize().Scale(distance))
+		}
+		distance -= d.Length()
+	}
+	last := path[len(path)-1]
+	return vector.New(float64(last.X), float64(last.Y))
+}
+
+// pathLength returns the length of a polyline
+func pathLength(path []image.Point) float64 {
+	length := 0.0
+	for i := 0; i < len(path)-1; i++ {
+		length += vector.New(float64(path[i+1].X-path[i].X), float64(path[i+1].Y-path[i].Y)).Length()
+	}
+	return length
+}
+
+// stepBadgeCenter returns the center of the step badge on the drawn path of the link
+func (l *Link) stepBadgeCenter(radius float64) vector.Vector {
+	if l.stepPosition == STEP_POSITION_SOURCE {
+		// Leave room for the source arrow head
+		return pointAlongPath(l.path, radius+float64(l.LineWidth)+8)
+	}
+	return pointAlongPath(l.path, pathLength(l.path)/2)
+}
+
+// DrawStep draws the step badge of the link. It is called after every link is drawn
+// so that badges are not hidden by other links.
+func (l *Link) DrawStep(img *image.RGBA, fontFile string) error {
+	if l.Step <= 0 || len(l.path) < 2 {
+		return nil
+	}
+	face, err := stepBadgeFace(fontFile)
+	if err != nil {
+		return fmt.Errorf("failed to prepare font face for step badge: %w", err)
+	}
+	text := strconv.Itoa(l.Step)
+	radius := stepBadgeRadiusFor(face, text)
+	drawStepBadge(img, face, l.stepBadgeCenter(radius), radius, text, l.lineColor)
+	return nil
+}
+
+// drawStepBadge draws an antialiased filled circle with the text centered in it.
+// The text is white, or black on light fill colors.
+func drawStepBadge(img *image.RGBA, face font.Face, center vector.Vector, radius float64, text string, fill color.RGBA) {
+	fillCircle(img, center, radius, fill)
+
+	textColor := color.RGBA{255, 255, 255, 255}
+	if 0.299*float64(fill.R)+0.587*float64(fill.G)+0.114*float64(fill.B) > 160 {
+		textColor = color.RGBA{0, 0, 0, 255}
+	}
+	metrics := face.Metrics()
+	width := font.MeasureString(face, text)
+	d := &font.Drawer{
+		Dst:  img,
+		Src:  image.NewUniform(textColor),
+		Face: face,
+		Dot: fixed.Point26_6{
+			X: fixed.Int26_6(center.X*64) - width/2,
+			Y: fixed.Int26_6(center.Y*64) + (metrics.Ascent-metrics.Descent)/2,
+		},
+	}
+	d.DrawString(text)
+}
+
+// stepText returns the text of a step in the legend: the first label of the link,
+// or the titles of its source and target
+func (l *Link) stepText() string {
+	if title := l.labelText(); title != "" {
+		return title
+	}
+	return l.endpointsText()
+}
+
+// labelText returns the first label of the link
+func (l *Link) labelText() string {
+	for _, label := range []*LinkLabel{
+		l.Labels.AutoRight, l.Labels.AutoLeft,
+		l.Labels.SourceRight, l.Labels.SourceLeft,
+		l.Labels.TargetRight, l.Labels.TargetLeft,
+	} {
+		if label != nil && label.Title != "" {
+			return label.Title
+		}
+	}
+	return ""
+}
+
+// endpointsText returns the titles of the source and target of the link. A link ending on
+// a link is described by the first label of that link, or its endpoints in parentheses.
+func (l *Link) endpointsText() string {
+	if l.Source.label == "" {
+		return ""
+	}
+	target := ""
+	if l.IsJunction() {
+		target = l.TargetLink.labelText()
+		if target == "" {
+			if endpoints := l.TargetLink.endpointsText(); endpoints != "" {
+				target = "(" + endpoints + ")"
+			}
+		}
+	} else if l.Target != nil {
+		target = l.Target.label
+	}
+	if target == "" {
+		return ""
+	}
+	return fmt.Sprintf("%s → %s", l.Source.label, target)
+}
+
+// DrawStepLegend returns a copy of img extended downwards with a legend listing
+// every step of the links in order, drawn on the background color with the font file of the diagram
+func DrawStepLegend(img *image.RGBA, links []*Link, background color.RGBA, fontFile string) (*image.RGBA, error) {
+	steps := []*Link{}
+	for _, l := range links {
+		if l.Step > 0 {
+			steps = append(steps, l)
+		}
+	}
+	if len(steps) == 0 {
+		return img, nil
+	}
+	sort.SliceStable(steps, func(i, j int) bool {
+		return steps[i].Step < steps[j].Step
+	})
+
+	badgeFace, err := stepBadgeFace(fontFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to prepare font face for step legend: %w", err)
+	}
+	textFace, err := loadFontFace(fontFile, stepLegendFontSize, FONT_WEIGHT_NORMAL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to prepare font face for step legend: %w", err)
+	}
+
+	radius := stepBadgeRadius
+	textWidth := 0
+	for _, l := range steps {
+		radius = math.Max(radius, stepBadgeRadiusFor(badgeFace, strconv.Itoa(l.Step)))
+		textWidth = max(textWidth, font.MeasureString(textFace, l.stepText()).Ceil())
+	}
+	badgeWidth := int(math.Ceil(radius * 2))
+	lineHeight := badgeWidth + 8
+	frame := image.Rect(0, 0,
+		stepLegendPadding*2+badgeWidth+10+textWidth,
+		stepLegendPadding*2+lineHeight*len(steps)-8,
+	)
+
+	bounds := img.Bounds()
+	frame = frame.Add(image.Point{bounds.Min.X + stepLegendMargin, bounds.Max.Y})
+	result, offset := extendImage(img, frame.Inset(-stepLegendMargin), background)
+	frame = frame.Add(offset)
+	drawBoxBorder(result, frame, legendBorderColor)
+
+	textColor := textColorOn(background)
+	metrics := textFace.Metrics()
+	for i, l := range steps {
+		text := strconv.Itoa(l.Step)
+		center := vector.New(
+			float64(frame.Min.X+stepLegendPadding)+radius,
+			float64(frame.Min.Y+stepLegendPadding+lineHeight*i)+radius,
+		)
+		drawStepBadge(result, badgeFace, center, stepBadgeRadiusFor(badgeFace, text), text, l.lineColor)
+		d := &font.Drawer{
+			Dst:  result,
+			Src:  image.NewUniform(textColor),
+			Face: textFace,
+			Dot: fixed.Point26_6{
+				X: fixed.I(frame.Min.X + stepLegendPadding + badgeWidth + 10),
+				Y: fixed.Int26_6(center.Y*64) + (metrics.Ascent-metrics.Descent)/2,
+			},
+		}
+		d.DrawString(l.stepText())
+	}
+	return result, nil
+}
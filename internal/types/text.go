@@ -0,0 +1,154 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package types
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+	"golang.org/x/image/font"
+)
+
+const (
+	FONT_WEIGHT_NORMAL = "normal"
+	FONT_WEIGHT_BOLD   = "bold"
+)
+
+// Space between the lines of resource titles
+const defaultLineSpacing = 10
+
+// validateFontWeight returns the font weight, normal when empty
+func validateFontWeight(weight string) (string, error) {
+	switch weight {
+	case "", FONT_WEIGHT_NORMAL:
+		return FONT_WEIGHT_NORMAL, nil
+	case FONT_WEIGHT_BOLD:
+		return FONT_WEIGHT_BOLD, nil
+	}
+	return "", fmt.Errorf("unknown font weight %s (allowed: normal, bold)", weight)
+}
+
+// boldFontFile returns the bold variant of a font file found next to it,
+// such as LiberationSans-Bold.ttf for LiberationSans-Regular.ttf, or "" when there is none
+func boldFontFile(fontFile string) string {
+	dir, name := filepath.Split(fontFile)
+	ext := filepath.Ext(name)
+	base := strings.TrimSuffix(name, ext)
+	candidates := []string{
+		strings.Replace(base, "Regular", "Bold", 1),
+		strings.Replace(base, "-Regular", "", 1) + "-Bold",
+		base + "-Bold",
+		base + " Bold",
+		base + "bd",
+	}
+	for _, candidate := range candidates {
+		if candidate == base {
+			continue
+		}
+		path := filepath.Join(dir, candidate+ext)
+		if _, err := os.Stat(path); err == nil {
+			return path
+		}
+	}
+	return ""
+}
+
+// loadFontFace returns a face of the font file (or goregular) at the given size, drawing the
+// characters missing from it with the fallback fonts. The bold weight uses gobold, or the bold
+// variant of the font file when it is found.
+func loadFontFace(fontFile string, size float64, weight string) (font.Face, error) {
+	fontFile = findFont(fontFile)
+	if fontFile == "goregular" || fontFile == "" {
+		// Use Go-fonts instead system fonts
+		fontFile = "goregular"
+		if weight == FONT_WEIGHT_BOLD {
+			fontFile = "gobold"
+		}
+	} else if weight == FONT_WEIGHT_BOLD {
+		if bold := boldFontFile(fontFile); bold != "" {
+			fontFile = bold
+		} else {
+			log.Infof("Bold variant of %s is not found. Use the regular weight.", fontFile)
+		}
+	}
+	f, err := parseFont(fontFile)
+	if err != nil {
+		return nil, err
+	}
+	face, err := f.newFace(size)
+	if err != nil {
+		return nil, err
+	}
+	return &fallbackFace{
+		fonts:   []*parsedFont{f},
+		faces:   []font.Face{face},
+		pending: fallbackChain(fontFile, weight),
+		size:    size,
+		runes:   map[rune]int{},
+	}, nil
+}
+
+// wrapText splits the text at newlines, and wraps each line at spaces so that it fits in
+// maxWidth pixels. Words wider than maxWidth are broken between characters.
+// With maxWidth 0, lines are only split at newlines.
+func wrapText(face font.Face, text string, maxWidth int) []string {
+	if maxWidth <= 0 {
+		return strings.Split(text, "\n")
+	}
+	fits := func(s string) bool {
+		return font.MeasureString(face, s).Ceil() <= maxWidth
+	}
+	lines := []string{}
+	for _, paragraph := range strings.Split(text, "\n") {
+		line := ""
+		for _, word := range strings.Fields(paragraph) {
+			if line != "" && fits(line+" "+word) {
+				line += " " + word
+				continue
+			}
+			if line != "" {
+				lines = append(lines, line)
+			}
+			// Break words too long for a line on their own
+			line = ""
+			for _, r := range word {
+				if line != "" && !fits(line+string(r)) {
+					lines = append(lines, line)
+					line = ""
+				}
+				line += string(r)
+			}
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+// SetFontSize sets the size of the title font. 0 uses the default size (24, or 30 for groups).
+func (r *Resource) SetFontSize(size float64) {
+	r.fontSize = size
+}
+
+// SetFontWeight sets the weight of the title font (normal or bold)
+func (r *Resource) SetFontWeight(weight string) error {
+	w, err := validateFontWeight(weight)
+	if err != nil {
+		return err
+	}
+	r.fontWeight = w
+	return nil
+}
+
+// SetLineSpacing sets the space in pixels between the lines of the title
+func (r *Resource) SetLineSpacing(spacing int) {
+	r.lineSpacing = spacing
+}
+
+// SetMaxTitleWidth sets the width in pixels the title is wrapped to. 0 disables wrapping.
+func (r *Resource) SetMaxTitleWidth(width int) {
+	r.maxTitleWidth = width
+}
@@ -0,0 +1,54 @@
+package types
+
+import (
+	"reflect"
+	"testing"
+
+	"golang.org/x/image/font"
+)
+
+func TestWrapText(t *testing.T) {
+	face, err := loadFontFace("", 24, FONT_WEIGHT_NORMAL)
+	if err != nil {
+		t.Fatalf("Failed to load font face: %v", err)
+	}
+	width := func(s string) int {
+		return font.MeasureString(face, s).Ceil()
+	}
+
+	tests := []struct {
+		name     string
+		text     string
+		maxWidth int
+		expected []string
+	}{
+		{"NoWrap", "Amazon S3 bucket\nlogs", 0, []string{"Amazon S3 bucket", "logs"}},
+		{"Fits", "Amazon S3", width("Amazon S3"), []string{"Amazon S3"}},
+		{"WrapAtSpaces", "Amazon S3 bucket", width("Amazon S3"), []string{"Amazon S3", "bucket"}},
+		{"KeepNewlines", "Amazon S3\nbucket for logs", max(width("Amazon S3"), width("bucket for")), []string{"Amazon S3", "bucket for", "logs"}},
+		{"EmptyLine", "a\n\nb", 100, []string{"a", "", "b"}},
+		{"BreakLongWord", "abcdef", width("abc"), []string{"abc", "def"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lines := wrapText(face, tt.text, tt.maxWidth)
+			if !reflect.DeepEqual(lines, tt.expected) {
+				t.Errorf("Expected %q, got %q", tt.expected, lines)
+			}
+		})
+	}
+}
+
+func TestLoadFontFace_Bold(t *testing.T) {
+	regular, err := loadFontFace("", 24, FONT_WEIGHT_NORMAL)
+	if err != nil {
+		t.Fatalf("Failed to load font face: %v", err)
+	}
+	bold, err := loadFontFace("", 24, FONT_WEIGHT_BOLD)
+	if err != nil {
+		t.Fatalf("Failed to load font face: %v", err)
+	}
+	if font.MeasureString(bold, "Amazon S3") <= font.MeasureString(regular, "Amazon S3") {
+		t.Errorf("Expected bold text to be wider than regular text")
+	}
+}
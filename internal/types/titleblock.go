@@ -0,0 +1,110 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package types
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/math/fixed"
+)
+
+const (
+	titleBlockTitleFontSize = 24.0
+	titleBlockFontSize      = 16.0
+	titleBlockRowSpacing    = 6 // Space between rows of the title block
+)
+
+// TitleBlockField is a row of the title block, such as the version or the author
+type TitleBlockField struct {
+	Name  string
+	Value string
+}
+
+// TitleBlock is a box with the title of the diagram and a row for each field,
+// drawn outside the diagram on the side given by Position
+type TitleBlock struct {
+	Title    string
+	Fields   []TitleBlockField
+	Position Windrose
+}
+
+// Draw returns a copy of img extended with the title block, drawn on the background color with
+// the font file of the diagram. It returns img when the title block is empty.
+func (tb *TitleBlock) Draw(img *image.RGBA, background color.RGBA, fontFile string) (*image.RGBA, error) {
+	if tb.Title == "" && len(tb.Fields) == 0 {
+		return img, nil
+	}
+	titleFace, err := loadFontFace(fontFile, titleBlockTitleFontSize, FONT_WEIGHT_BOLD)
+	if err != nil {
+		return nil, fmt.Errorf("failed to prepare font face for title block: %w", err)
+	}
+	nameFace, err := loadFontFace(fontFile, titleBlockFontSize, FONT_WEIGHT_BOLD)
+	if err != nil {
+		return nil, fmt.Errorf("failed to prepare font face for title block: %w", err)
+	}
+	valueFace, err := loadFontFace(fontFile, titleBlockFontSize, FONT_WEIGHT_NORMAL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to prepare font face for title block: %w", err)
+	}
+
+	titleMetrics := titleFace.Metrics()
+	rowMetrics := valueFace.Metrics()
+	titleHeight := 0
+	width := 0
+	if tb.Title != "" {
+		titleHeight = (titleMetrics.Ascent + titleMetrics.Descent).Ceil()
+		width = font.MeasureString(titleFace, tb.Title).Ceil()
+	}
+	rowHeight := (rowMetrics.Ascent + rowMetrics.Descent).Ceil()
+	nameWidth := 0
+	for _, field := range tb.Fields {
+		nameWidth = max(nameWidth, font.MeasureString(nameFace, field.Name).Ceil())
+	}
+	for _, field := range tb.Fields {
+		width = max(width, nameWidth+legendGap+font.MeasureString(valueFace, field.Value).Ceil())
+	}
+	height := titleHeight + (rowHeight+titleBlockRowSpacing)*len(tb.Fields)
+	if tb.Title == "" {
+		height -= titleBlockRowSpacing
+	}
+	size := image.Point{legendPadding*2 + width, legendPadding*2 + height}
+	frame, err := legendFrame(img.Bounds(), size, tb.Position)
+	if err != nil {
+		return nil, err
+	}
+	result, offset := extendImage(img, frame.Inset(-legendMargin), background)
+	frame = frame.Add(offset)
+
+	drawBoxBorder(result, frame, legendBorderColor)
+
+	textColor := image.NewUniform(textColorOn(background))
+	x := frame.Min.X + legendPadding
+	y := frame.Min.Y + legendPadding
+	if tb.Title != "" {
+		d := &font.Drawer{Dst: result, Src: textColor, Face: titleFace}
+		d.Dot = fixed.Point26_6{X: fixed.I(x), Y: fixed.I(y) + titleMetrics.Ascent}
+		d.DrawString(tb.Title)
+		y += titleHeight + titleBlockRowSpacing
+		// Rule between the title and the fields
+		if len(tb.Fields) > 0 {
+			for rx := frame.Min.X; rx < frame.Max.X; rx++ {
+				result.SetRGBA(rx, y-titleBlockRowSpacing/2, legendBorderColor)
+			}
+		}
+	}
+	for _, field := range tb.Fields {
+		baseline := fixed.I(y) + rowMetrics.Ascent
+		d := &font.Drawer{Dst: result, Src: textColor, Face: nameFace}
+		d.Dot = fixed.Point26_6{X: fixed.I(x), Y: baseline}
+		d.DrawString(field.Name)
+		d = &font.Drawer{Dst: result, Src: textColor, Face: valueFace}
+		d.Dot = fixed.Point26_6{X: fixed.I(x + nameWidth + legendGap), Y: baseline}
+		d.DrawString(field.Value)
+		y += rowHeight + titleBlockRowSpacing
+	}
+	return result, nil
+}
@@ -142,3 +142,35 @@ func TestConvertWindrose(t *testing.T) {
 		t.Error("Expected error for invalid position")
 	}
 }
+
+func TestDarkColors(t *testing.T) {
+	testCases := []struct {
+		name           string
+		input          color.RGBA
+		expectedFill   color.RGBA
+		expectedStroke color.RGBA
+	}{
+		{"black", color.RGBA{0, 0, 0, 255}, color.RGBA{255, 255, 255, 255}, color.RGBA{255, 255, 255, 255}},
+		{"white", color.RGBA{255, 255, 255, 255}, color.RGBA{0, 0, 0, 255}, color.RGBA{255, 255, 255, 255}},
+		{"light tint", color.RGBA{240, 235, 255, 128}, color.RGBA{5, 0, 20, 128}, color.RGBA{240, 235, 255, 128}},
+		{"dark green", color.RGBA{0, 100, 0, 255}, color.RGBA{155, 255, 155, 255}, color.RGBA{155, 255, 155, 255}},
+		{"transparent", color.RGBA{0, 0, 0, 0}, color.RGBA{255, 255, 255, 0}, color.RGBA{255, 255, 255, 0}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := DarkFillColor(tc.input); got != tc.expectedFill {
+				t.Errorf("DarkFillColor(%v) = %v, expected %v", tc.input, got, tc.expectedFill)
+			}
+			if got := DarkStrokeColor(tc.input); got != tc.expectedStroke {
+				t.Errorf("DarkStrokeColor(%v) = %v, expected %v", tc.input, got, tc.expectedStroke)
+			}
+		})
+	}
+
+	SetDarkMode(true)
+	defer SetDarkMode(false)
+	if r := new(Resource).Init(); *r.labelColor == (color.RGBA{0, 0, 0, 255}) {
+		t.Error("Expected a light default title color in dark mode")
+	}
+}
@@ -0,0 +1,80 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package types
+
+import (
+	"image"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// Waypoint is a point an orthogonal link must pass through.
+// It is either a point relative to the top-left corner of the canvas, or a windrose
+// position on a resource when Resource is set.
+type Waypoint struct {
+	Point    image.Point
+	Resource *Resource
+	Position Windrose
+}
+
+// resolve returns the point of the waypoint after layout is complete
+func (w Waypoint) resolve(canvas *Resource) (image.Point, error) {
+	if w.Resource == nil {
+		return canvas.GetBindings().Min.Add(w.Point), nil
+	}
+	return calcPosition(w.Resource.GetBindings(), w.Position)
+}
+
+func (l *Link) SetWaypoints(waypoints []Waypoint) {
+	l.Waypoints = waypoints
+}
+
+// calculateWaypointPath returns the control points of an axis-aligned path from sourcePt to
+// targetPt through the waypoints in order. Each leg keeps the axis of the previous segment
+// before bending, and the path leaves and enters resources along their windrose directions.
+func (l *Link) calculateWaypointPath(sourcePt, targetPt image.Point) ([]image.Point, error) {
+	sourceDir := routingDirections[windroseToRoutingDirection(l.SourcePosition)]
+	targetDir := routingDirections[windroseToRoutingDirection(l.TargetPosition)]
+
+	canvas := l.Source
+	for canvas.parent != nil {
+		canvas = canvas.parent
+	}
+
+	path := []image.Point{sourcePt, sourcePt.Add(sourceDir.Mul(routingStubLength))}
+	horizontal := sourceDir.Y == 0
+	for _, w := range l.Waypoints {
+		pt, err := w.resolve(canvas)
+		if err != nil {
+			return nil, err
+		}
+		path, horizontal = appendOrthogonalLeg(path, pt, horizontal)
+	}
+
+	// Enter the target stub along the target axis so that the last segment stays straight
+	targetStub := targetPt.Add(targetDir.Mul(routingStubLength))
+	path, _ = appendOrthogonalLeg(path, targetStub, targetDir.Y != 0)
+	path = append(path, targetPt)
+
+	log.Infof("Waypoint path: %v", path)
+	return simplifyOrthogonalPath(path), nil
+}
+
+// appendOrthogonalLeg appends an L-shaped leg to pt, moving along the horizontal axis first
+// when horizontal is set. It returns the new path and the axis of the last segment.
+func appendOrthogonalLeg(path []image.Point, pt image.Point, horizontal bool) ([]image.Point, bool) {
+	last := path[len(path)-1]
+	switch {
+	case last == pt:
+		return path, horizontal
+	case last.X == pt.X:
+		return append(path, pt), false
+	case last.Y == pt.Y:
+		return append(path, pt), true
+	case horizontal:
+		return append(path, image.Point{pt.X, last.Y}, pt), false
+	default:
+		return append(path, image.Point{last.X, pt.Y}, pt), true
+	}
+}